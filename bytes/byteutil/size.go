@@ -2,6 +2,9 @@ package byteutil
 
 import (
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 )
 
 const (
@@ -38,6 +41,52 @@ var (
 
 type Size int64
 
+// ParseSize parses a size string such as "512MiB", "1.5GB" or "100".
+// The unit is one of B, KB, MB, GB, TB, PB, EB, KiB, MiB, GiB, TiB, PiB and EiB, and it is case-insensitive.
+// The number without the unit is the number of bytes.
+func ParseSize(s string) (Size, error) {
+
+	s = strings.TrimSpace(s)
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= '0' && r <= '9') && r != '.' && r != '-' && r != '+'
+	})
+	if end < 0 {
+		end = len(s)
+	}
+
+	number, unit := s[:end], strings.TrimSpace(s[end:])
+	factor := Size(1)
+	if unit != "" && !strings.EqualFold(unit, "B") {
+		factor = 0
+		for f, name := range sizeToStringMap {
+			if strings.EqualFold(unit, name) {
+				factor = f
+				break
+			}
+		}
+		if factor == 0 {
+			return 0, fmt.Errorf("invalid size unit `%s`", unit)
+		}
+	}
+
+	if i, err := strconv.ParseInt(number, 10, 64); err == nil {
+		if i > math.MaxInt64/int64(factor) || i < math.MinInt64/int64(factor) {
+			return 0, fmt.Errorf("size out of range `%s`", s)
+		}
+		return Size(i) * factor, nil
+	}
+
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size `%s`", s)
+	}
+	f *= float64(factor)
+	if f >= math.MaxInt64 || f < math.MinInt64 {
+		return 0, fmt.Errorf("size out of range `%s`", s)
+	}
+	return Size(f), nil
+}
+
 func (i Size) Int64() int64 {
 	return int64(i)
 }
@@ -43,3 +43,39 @@ func TestSize_Format(t *testing.T) {
 		}
 	}
 }
+
+func TestParseSize(t *testing.T) {
+	testSuites := []struct {
+		s       string
+		size    byteutil.Size
+		invalid bool
+	}{
+		{s: "0", size: 0},
+		{s: "100", size: 100},
+		{s: "100B", size: 100},
+		{s: "512MiB", size: 512 * byteutil.MiB},
+		{s: "512mib", size: 512 * byteutil.MiB},
+		{s: "1.5GB", size: 1500 * byteutil.MB},
+		{s: "2 KB", size: 2 * byteutil.KB},
+		{s: "-1KiB", size: -byteutil.KiB},
+		{s: "8EiB", invalid: true},
+		{s: "10XB", invalid: true},
+		{s: "MiB", invalid: true},
+	}
+
+	for i, suite := range testSuites {
+		size, err := byteutil.ParseSize(suite.s)
+		if suite.invalid {
+			if err == nil {
+				testutil.Failf(t, "suite:%d %s must be an error: %d", i, suite.s, size)
+			}
+			continue
+		}
+		if err != nil {
+			testutil.Failf(t, "suite:%d %v", i, err)
+		}
+		if size != suite.size {
+			testutil.Failf(t, "suite:%d want:%d got:%d", i, suite.size, size)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// initialisms is the words written in upper case in Go identifiers.
+var initialisms = map[string]bool{
+	"API": true, "ASCII": true, "CPU": true, "CSS": true, "CSV": true, "DNS": true, "EOF": true,
+	"GUID": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true,
+	"OS": true, "SKU": true, "SQL": true, "SSH": true, "TCP": true, "TLS": true, "TTL": true,
+	"UDP": true, "UI": true, "URI": true, "URL": true, "UTF8": true, "UUID": true, "XML": true,
+}
+
+// generate returns the formatted Go source code of the struct named typeName.
+// If noHeader is true, the fields are decoded in the order of the columns.
+func generate(pkgName string, typeName string, columns []*column, noHeader bool) ([]byte, error) {
+
+	var (
+		buf     = new(bytes.Buffer)
+		names   = make(map[string]int)
+		useTime = false
+	)
+
+	for _, c := range columns {
+		if c.typ == columnTypeTime {
+			useTime = true
+		}
+	}
+
+	fmt.Fprintln(buf, "// Code generated by csv2struct.")
+	fmt.Fprintln(buf)
+	fmt.Fprintf(buf, "package %s\n\n", pkgName)
+	if useTime {
+		fmt.Fprintln(buf, `import "time"`)
+		fmt.Fprintln(buf)
+	}
+
+	fmt.Fprintf(buf, "// %s is the record of CSV data.\n", typeName)
+	fmt.Fprintf(buf, "type %s struct {\n", typeName)
+	for _, c := range columns {
+
+		name := identifier(c.header)
+		if c.header == "" {
+			name = fmt.Sprintf("Column%d", c.index+1)
+		}
+		names[name]++
+		if n := names[name]; n > 1 {
+			name += strconv.Itoa(n)
+		}
+
+		switch {
+		case c.header == "" && !noHeader:
+			// the empty header cannot be written in the tag, so the column is ignored
+			fmt.Fprintf(buf, "// %s cannot be decoded because the header is empty.\n", name)
+			fmt.Fprintf(buf, "%s %s `csv:\"-\"`\n", name, c.goType())
+		case strings.Contains(c.header, ","):
+			// the header cannot be written in the tag, so the column is ignored
+			fmt.Fprintf(buf, "// %s cannot be decoded because the header %q contains a comma.\n", name, c.header)
+			fmt.Fprintf(buf, "%s %s `csv:\"-\"`\n", name, c.goType())
+		default:
+			fmt.Fprintf(buf, "%s %s %s\n", name, c.goType(), structTag(c.tag()))
+		}
+	}
+	fmt.Fprintln(buf, "}")
+
+	return format.Source(buf.Bytes())
+}
+
+// goType returns the Go type of the column. The nullable column is a pointer, except for string.
+func (c *column) goType() string {
+
+	var t string
+	switch c.typ {
+	case columnTypeInt:
+		t = "int"
+	case columnTypeInt64:
+		t = "int64"
+	case columnTypeFloat:
+		t = "float64"
+	case columnTypeBool:
+		t = "bool"
+	case columnTypeTime:
+		t = "time.Time"
+	default:
+		return "string"
+	}
+
+	if c.nullable {
+		return "*" + t
+	}
+	return t
+}
+
+// tag returns the value of the struct tag compatible with csvutil.Decoder.
+func (c *column) tag() string {
+
+	tag := c.header
+	if c.header == "" {
+		// the name is not used when the header is not used
+		tag = fmt.Sprintf("Column%d", c.index+1)
+	}
+	if c.typ == columnTypeTime && c.layout != time.RFC3339Nano {
+		tag += ",layout=" + c.layout
+	}
+
+	return "csv:" + strconv.Quote(tag)
+}
+
+// structTag returns the struct tag literal. The raw string literal is used unless the tag contains a back quote.
+func structTag(tag string) string {
+	if strings.Contains(tag, "`") {
+		return strconv.Quote(tag)
+	}
+	return "`" + tag + "`"
+}
+
+// identifier converts the header to the exported Go identifier.
+// The header is split into the words at the characters other than letters and digits, and at the lower-to-upper case boundaries.
+func identifier(header string) string {
+
+	var (
+		words []string
+		word  []rune
+		prev  rune
+	)
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = word[:0]
+		}
+	}
+	for _, r := range header {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+		prev = r
+	}
+	flush()
+
+	b := new(strings.Builder)
+	for _, w := range words {
+		upper := strings.ToUpper(w)
+		if initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		runes := []rune(strings.ToLower(w))
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+
+	name := b.String()
+	if name == "" {
+		return "Column"
+	}
+	if first := []rune(name)[0]; !unicode.IsLetter(first) || !unicode.IsUpper(first) {
+		// the identifier must start with an upper case letter to be exported
+		name = "X" + name
+	}
+	return name
+}
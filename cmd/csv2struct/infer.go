@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"go.nanasi880.dev/x/encoding/csvutil"
+)
+
+// columnType is the Go type inferred from the values of a column.
+type columnType int
+
+const (
+	columnTypeUnknown columnType = iota // columnTypeUnknown is the column which has no values.
+	columnTypeBool
+	columnTypeInt
+	columnTypeInt64
+	columnTypeFloat
+	columnTypeTime
+	columnTypeString
+)
+
+// timeLayouts is the layouts of time.Time tried by the type inference.
+// time.RFC3339Nano is the default layout of csvutil, so the `layout` tag option is not needed.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04:05",
+	"2006-01-02",
+	"2006/01/02",
+}
+
+// column is the column of CSV data.
+type column struct {
+	header   string     // header is the header of the column. It is empty if the header is not used.
+	index    int        // index is the 0-based index of the column.
+	typ      columnType // typ is the type inferred from the non-empty values.
+	layout   string     // layout is the layout of time.Time if typ is columnTypeTime.
+	nullable bool       // nullable is true if the column has empty values.
+	values   []string   // values is the non-empty sample values.
+}
+
+// inferColumns reads the header and the sample rows, and infers the types of the columns.
+// If rows is 0, all rows are used.
+func inferColumns(dec *csvutil.Decoder, rows int) ([]*column, error) {
+
+	var columns []*column
+	for n := 0; rows <= 0 || n < rows; n++ {
+
+		var record csvutil.Record
+		err := dec.DecodeNext(&record)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if columns == nil {
+			for i, header := range record.Header {
+				columns = append(columns, &column{header: header, index: i})
+			}
+		}
+		for i := len(columns); i < len(record.Values); i++ {
+			// the header is not used or the row is longer than the header
+			columns = append(columns, &column{header: "", index: i})
+		}
+
+		for i, value := range record.Values {
+			if value == "" {
+				columns[i].nullable = true
+				continue
+			}
+			columns[i].values = append(columns[i].values, value)
+		}
+	}
+
+	if columns == nil {
+		return nil, fmt.Errorf("no data")
+	}
+
+	for _, c := range columns {
+		c.infer()
+	}
+	return columns, nil
+}
+
+// infer chooses the first type which accepts all the sample values.
+// The candidates are tried in the order of int, int64, float64, bool, time.Time (for each layout) and string.
+func (c *column) infer() {
+
+	if len(c.values) == 0 {
+		c.typ = columnTypeUnknown
+		return
+	}
+
+	candidates := []struct {
+		typ    columnType
+		accept func(s string) bool
+	}{
+		{typ: columnTypeInt, accept: func(s string) bool {
+			v, err := strconv.ParseInt(s, 10, 64)
+			return err == nil && v >= math.MinInt32 && v <= math.MaxInt32
+		}},
+		{typ: columnTypeInt64, accept: func(s string) bool {
+			_, err := strconv.ParseInt(s, 10, 64)
+			return err == nil
+		}},
+		{typ: columnTypeFloat, accept: func(s string) bool {
+			v, err := strconv.ParseFloat(s, 64)
+			return err == nil && !math.IsNaN(v) && !math.IsInf(v, 0)
+		}},
+		{typ: columnTypeBool, accept: func(s string) bool {
+			_, err := strconv.ParseBool(s)
+			return err == nil
+		}},
+	}
+
+	for _, candidate := range candidates {
+		if all(c.values, candidate.accept) {
+			c.typ = candidate.typ
+			return
+		}
+	}
+
+	for _, layout := range timeLayouts {
+		layout := layout
+		if all(c.values, func(s string) bool {
+			_, err := time.Parse(layout, s)
+			return err == nil
+		}) {
+			c.typ = columnTypeTime
+			c.layout = layout
+			return
+		}
+	}
+
+	c.typ = columnTypeString
+}
+
+func all(values []string, accept func(s string) bool) bool {
+	for _, v := range values {
+		if !accept(v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+// csv2struct generates a Go struct from the header and the sample rows of CSV data.
+// The generated struct can be decoded by csvutil.Decoder.
+//
+// Usage:
+//
+//	csv2struct [flags] [file]
+//
+// If the file is omitted, CSV data is read from the standard input.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"go.nanasi880.dev/x/encoding/csvutil"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "csv2struct:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+
+	var (
+		flags      = flag.NewFlagSet("csv2struct", flag.ContinueOnError)
+		typeName   = flags.String("type", "Record", "the name of the generated struct")
+		pkgName    = flags.String("package", "main", "the package name of the generated code")
+		sampleRows = flags.Int("rows", 100, "the number of the rows to infer the types. If 0, all rows are used")
+		comma      = flags.String("comma", ",", "the field delimiter")
+		noHeader   = flags.Bool("noheader", false, "the input has no header. The columns are named Column1, Column2, ...")
+		output     = flags.String("o", "", "the output file. If empty, the code is written to the standard output")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: csv2struct [flags] [file]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if utf8.RuneCountInString(*comma) != 1 {
+		return fmt.Errorf("the comma must be a single character: %q", *comma)
+	}
+	commaRune, _ := utf8.DecodeRuneInString(*comma)
+
+	in := stdin
+	switch flags.NArg() {
+	case 0:
+	case 1:
+		f, err := os.Open(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	default:
+		flags.Usage()
+		return fmt.Errorf("too many arguments")
+	}
+
+	dec := csvutil.NewDecoder(in)
+	dec.Comma = commaRune
+	dec.UseHeader = !*noHeader
+	dec.FieldsPerRecord = -1
+
+	columns, err := inferColumns(dec, *sampleRows)
+	if err != nil {
+		return err
+	}
+
+	code, err := generate(*pkgName, *typeName, columns, *noHeader)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err := stdout.Write(code)
+		return err
+	}
+	return os.WriteFile(*output, code, 0666)
+}
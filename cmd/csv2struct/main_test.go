@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+
+	const input = "user id,Name,score,active,created_at,birthday,count,2nd col,\n" +
+		"1,alice,1.5,true,2021-01-02T03:04:05Z,2000-01-02,1,a,\n" +
+		"2,bob,,false,2021-01-02T03:04:05Z,,9999999999,\"b\nb\",\n"
+
+	const want = "// Code generated by csv2struct.\n" +
+		"\n" +
+		"package model\n" +
+		"\n" +
+		"import \"time\"\n" +
+		"\n" +
+		"// User is the record of CSV data.\n" +
+		"type User struct {\n" +
+		"\tUserID    int        `csv:\"user id\"`\n" +
+		"\tName      string     `csv:\"Name\"`\n" +
+		"\tScore     *float64   `csv:\"score\"`\n" +
+		"\tActive    bool       `csv:\"active\"`\n" +
+		"\tCreatedAt time.Time  `csv:\"created_at\"`\n" +
+		"\tBirthday  *time.Time `csv:\"birthday,layout=2006-01-02\"`\n" +
+		"\tCount     int64      `csv:\"count\"`\n" +
+		"\tX2ndCol   string     `csv:\"2nd col\"`\n" +
+		"\t// Column9 cannot be decoded because the header is empty.\n" +
+		"\tColumn9 string `csv:\"-\"`\n" +
+		"}\n"
+
+	out := new(strings.Builder)
+	if err := run([]string{"-type", "User", "-package", "model"}, strings.NewReader(input), out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != want {
+		t.Fatalf("want:\n%s\ngot:\n%s", want, out.String())
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+
+	testSuites := []struct {
+		header string
+		want   string
+	}{
+		{header: "id", want: "ID"},
+		{header: "user_id", want: "UserID"},
+		{header: "homePageUrl", want: "HomePageURL"},
+		{header: "First Name", want: "FirstName"},
+		{header: "price (USD)", want: "PriceUsd"},
+		{header: "1st", want: "X1st"},
+		{header: "日付", want: "X日付"},
+		{header: "---", want: "Column"},
+	}
+
+	for _, suite := range testSuites {
+		if got := identifier(suite.header); got != suite.want {
+			t.Fatalf("%s: want: %s got: %s", suite.header, suite.want, got)
+		}
+	}
+}
+
+func TestRun_NonFinite(t *testing.T) {
+
+	const input = "ratio,score\n" +
+		"NaN,1e3\n" +
+		"Inf,-2.5\n"
+
+	const want = "// Code generated by csv2struct.\n" +
+		"\n" +
+		"package main\n" +
+		"\n" +
+		"// Record is the record of CSV data.\n" +
+		"type Record struct {\n" +
+		"\tRatio string  `csv:\"ratio\"`\n" +
+		"\tScore float64 `csv:\"score\"`\n" +
+		"}\n"
+
+	out := new(strings.Builder)
+	if err := run(nil, strings.NewReader(input), out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != want {
+		t.Fatalf("want:\n%s\ngot:\n%s", want, out.String())
+	}
+}
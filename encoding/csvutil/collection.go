@@ -0,0 +1,198 @@
+package csvutil
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// expandSeparator is the separator between the name of the field and the index or key of the expanded column.
+	expandSeparator = "_"
+
+	// defaultJoinSeparator is the separator of the joined slice if `join` tag option has no value.
+	defaultJoinSeparator = ";"
+)
+
+// fieldKind is how the value of the field is mapped to the column.
+//
+// The slice and map fields can be mapped to the columns by the options of the csv struct tag.
+//
+//	expand=3           the slice is expanded into the columns name_1, name_2 and name_3.
+//	expand,keys=a|b    the map is expanded into the columns name_a and name_b.
+//	expand             the map is collected from the columns which have the prefix name_ (decode only).
+//	join or join=|     the slice is joined into one cell with the separator. (default: ;)
+//	join=','           the separator which contains commas is quoted by single quotes.
+type fieldKind int
+
+const (
+	fieldKindValue        fieldKind = iota // the value is mapped to the column.
+	fieldKindSliceElement                  // the element of the slice is mapped to the column.
+	fieldKindMapElement                    // the value of the map is mapped to the column.
+	fieldKindMapPrefix                     // the columns which have the prefix are collected into the map.
+	fieldKindJoined                        // the elements of the slice are joined into the column.
+	fieldKindPadding                       // the column is not mapped to any field, and it is encoded as the empty cell.
+)
+
+// appendCollectionFields appends the field, or the expanded fields if the field is a slice or map with the options.
+func appendCollectionFields(fields *[]field, leaf field, t reflect.Type, options tagOptions) error {
+
+	switch {
+	case options.has("join"):
+		if t.Kind() != reflect.Slice {
+			return fmt.Errorf("join option requires a slice")
+		}
+		leaf.kind = fieldKindJoined
+		leaf.join = options["join"]
+		if leaf.join == "" {
+			leaf.join = defaultJoinSeparator
+		}
+		*fields = append(*fields, leaf)
+		return nil
+
+	case options.has("expand") && t.Kind() == reflect.Slice:
+		count, err := strconv.Atoi(options["expand"])
+		if err != nil || count <= 0 {
+			return fmt.Errorf("expand option of a slice requires the number of columns")
+		}
+		for i := 0; i < count; i++ {
+			f := leaf
+			f.name = leaf.name + expandSeparator + strconv.Itoa(i+1)
+			f.kind = fieldKindSliceElement
+			f.element = i
+			f.count = count
+			if leaf.column >= 0 {
+				f.column = leaf.column + i
+			}
+			*fields = append(*fields, f)
+		}
+		return nil
+
+	case options.has("expand") && t.Kind() == reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return fmt.Errorf("expand option of a map requires string keys")
+		}
+		keys, ok := options["keys"]
+		if !ok {
+			f := leaf
+			f.name = leaf.name + expandSeparator
+			f.kind = fieldKindMapPrefix
+			f.required = false
+			*fields = append(*fields, f)
+			return nil
+		}
+		for i, key := range strings.Split(keys, "|") {
+			f := leaf
+			if leaf.column >= 0 {
+				f.column = leaf.column + i
+			}
+			f.name = leaf.name + expandSeparator + key
+			f.kind = fieldKindMapElement
+			f.mapKey = key
+			*fields = append(*fields, f)
+		}
+		return nil
+
+	case options.has("expand"):
+		return fmt.Errorf("expand option requires a slice or map")
+
+	default:
+		*fields = append(*fields, leaf)
+		return nil
+	}
+}
+
+// encodeField is encodes the value of the field into a cell.
+func (e *Encoder) encodeField(fv reflect.Value, f *field) (string, error) {
+
+	switch f.kind {
+	case fieldKindSliceElement:
+		if f.element == f.count-1 && fv.Len() > f.count {
+			return "", fmt.Errorf("field %s: the length %d exceeds the expanded columns %d", f.path, fv.Len(), f.count)
+		}
+		if f.element >= fv.Len() {
+			return f.format.nilString(e.Nil), nil
+		}
+		return e.encodeValue(fv.Index(f.element), f)
+
+	case fieldKindMapElement:
+		mv := fv.MapIndex(reflect.ValueOf(f.mapKey).Convert(fv.Type().Key()))
+		if !mv.IsValid() {
+			return f.format.nilString(e.Nil), nil
+		}
+		return e.encodeValue(mv, f)
+
+	case fieldKindMapPrefix:
+		return "", fmt.Errorf("field %s: keys option is required to encode the expanded map", f.path)
+
+	case fieldKindPadding:
+		return "", nil
+
+	case fieldKindJoined:
+		if fv.IsNil() {
+			return f.format.nilString(e.Nil), nil
+		}
+		elems := make([]string, 0, fv.Len())
+		for i := 0; i < fv.Len(); i++ {
+			encoded, err := e.encodeValue(fv.Index(i), f)
+			if err != nil {
+				return "", err
+			}
+			elems = append(elems, encoded)
+		}
+		return strings.Join(elems, f.join), nil
+
+	default:
+		return e.encodeValue(fv, f)
+	}
+}
+
+// decodeField is decodes the cell into the value of the field.
+// The cell of Nil is skipped for the slice elements and the map values unless they are pointers.
+func (d *Decoder) decodeField(raw string, fv reflect.Value, f *field) error {
+
+	switch f.kind {
+	case fieldKindSliceElement:
+		if raw == f.format.nilString(d.Nil) && fv.Type().Elem().Kind() != reflect.Ptr {
+			return nil
+		}
+		if fv.Len() <= f.element {
+			grown := reflect.MakeSlice(fv.Type(), f.element+1, f.element+1)
+			reflect.Copy(grown, fv)
+			fv.Set(grown)
+		}
+		return d.decodeValue(raw, fv.Index(f.element), f)
+
+	case fieldKindMapElement, fieldKindMapPrefix:
+		if raw == f.format.nilString(d.Nil) && fv.Type().Elem().Kind() != reflect.Ptr {
+			return nil
+		}
+		if fv.IsNil() {
+			fv.Set(reflect.MakeMap(fv.Type()))
+		}
+		value := reflect.New(fv.Type().Elem()).Elem()
+		if err := d.decodeValue(raw, value, f); err != nil {
+			return err
+		}
+		fv.SetMapIndex(reflect.ValueOf(f.mapKey).Convert(fv.Type().Key()), value)
+		return nil
+
+	case fieldKindJoined:
+		if raw == f.format.nilString(d.Nil) || raw == "" {
+			return nil
+		}
+		elems := strings.Split(raw, f.join)
+		slice := reflect.MakeSlice(fv.Type(), len(elems), len(elems))
+		for i, elem := range elems {
+			if err := d.decodeValue(elem, slice.Index(i), f); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+
+	default:
+		return d.decodeValue(raw, fv, f)
+	}
+}
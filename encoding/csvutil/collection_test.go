@@ -0,0 +1,104 @@
+package csvutil_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.nanasi880.dev/x/encoding/csvutil"
+)
+
+func TestCollectionFields(t *testing.T) {
+
+	type csvData struct {
+		Name   string            `csv:"name"`
+		Scores []int             `csv:"score,expand=3"`
+		Tags   []string          `csv:"tags,join=|"`
+		Attrs  map[string]string `csv:"attr,expand,keys=color|size"`
+	}
+
+	d := []csvData{
+		{Name: "Bob", Scores: []int{10, 20, 30}, Tags: []string{"a", "b"}, Attrs: map[string]string{"color": "red", "size": "L"}},
+		{Name: "Alice", Scores: []int{40}, Tags: nil, Attrs: map[string]string{"size": "S"}},
+	}
+
+	encoded, err := csvutil.MarshalString(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const want = "name,score_1,score_2,score_3,tags,attr_color,attr_size\n" +
+		"Bob,10,20,30,a|b,red,L\n" +
+		"Alice,40,,,,,S\n"
+	if encoded != want {
+		t.Fatalf("want: %s got: %s", want, encoded)
+	}
+
+	var decoded []csvData
+	if err := csvutil.UnmarshalString(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, d) {
+		t.Fatal(decoded)
+	}
+
+	if _, err := csvutil.MarshalString(csvData{Scores: []int{1, 2, 3, 4}}); err == nil {
+		t.Fatal("the slice longer than the expanded columns must be rejected")
+	}
+}
+
+func TestCollectionFields_JoinComma(t *testing.T) {
+
+	type csvData struct {
+		Tags []string `csv:"tags,join=','"`
+	}
+
+	d := []csvData{{Tags: []string{"x", "y"}}}
+
+	encoded, err := csvutil.MarshalString(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded != "tags\n\"x,y\"\n" {
+		t.Fatal(encoded)
+	}
+
+	var decoded []csvData
+	if err := csvutil.UnmarshalString(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, d) {
+		t.Fatal(decoded)
+	}
+
+	// the unquoted comma is not the separator, but the empty option
+	if _, err := csvutil.MarshalString([]struct {
+		Tags []string `csv:"tags,join=,"`
+	}{{Tags: []string{"x", "y"}}}); err == nil {
+		t.Fatal("the empty option must be rejected")
+	}
+}
+
+func TestDecoder_Decode_CollectMapByPrefix(t *testing.T) {
+
+	type csvData struct {
+		ID    int            `csv:"id"`
+		Stock map[string]int `csv:"stock,expand"`
+	}
+
+	const csvString = "id,stock_tokyo,stock_osaka,other\n1,10,,x\n"
+
+	var out []csvData
+	if err := csvutil.UnmarshalString(csvString, &out); err != nil {
+		t.Fatal(err)
+	}
+	want := []csvData{{ID: 1, Stock: map[string]int{"tokyo": 10}}}
+	if !reflect.DeepEqual(out, want) {
+		t.Fatal(out)
+	}
+
+	enc := csvutil.NewEncoder(new(strings.Builder))
+	if err := enc.Encode(out); err == nil {
+		t.Fatal("the map without keys option cannot be encoded")
+	}
+}
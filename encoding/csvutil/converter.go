@@ -0,0 +1,115 @@
+package csvutil
+
+import (
+	"reflect"
+	"sync"
+)
+
+// EncodeFunc is the function which encodes the value of the registered type into a CSV cell.
+// The v is the value of the registered type.
+type EncodeFunc func(v interface{}) (string, error)
+
+// DecodeFunc is the function which decodes a CSV cell into the value of the registered type.
+// The v is the pointer of the value of the registered type.
+type DecodeFunc func(s string, v interface{}) error
+
+var (
+	defaultConvertersMutex   sync.RWMutex
+	defaultEncodeFuncs       = make(map[reflect.Type]EncodeFunc)
+	defaultDecodeFuncs       = make(map[reflect.Type]DecodeFunc)
+	defaultConvertersVersion uint64 // defaultConvertersVersion is incremented on every registration, so that the cached plans are invalidated.
+)
+
+// RegisterEncodeFunc is register the default EncodeFunc of the type t to all encoders.
+// The EncodeFunc registered by Encoder.Register takes precedence.
+// If f is nil, the EncodeFunc of the type t is unregistered.
+func RegisterEncodeFunc(t reflect.Type, f EncodeFunc) {
+	defaultConvertersMutex.Lock()
+	defer defaultConvertersMutex.Unlock()
+
+	defaultConvertersVersion++
+	if f == nil {
+		delete(defaultEncodeFuncs, t)
+		return
+	}
+	defaultEncodeFuncs[t] = f
+}
+
+// RegisterDecodeFunc is register the default DecodeFunc of the type t to all decoders.
+// The DecodeFunc registered by Decoder.Register takes precedence.
+// If f is nil, the DecodeFunc of the type t is unregistered.
+func RegisterDecodeFunc(t reflect.Type, f DecodeFunc) {
+	defaultConvertersMutex.Lock()
+	defer defaultConvertersMutex.Unlock()
+
+	defaultConvertersVersion++
+	if f == nil {
+		delete(defaultDecodeFuncs, t)
+		return
+	}
+	defaultDecodeFuncs[t] = f
+}
+
+// convertersVersion returns the version of the default converters.
+func convertersVersion() uint64 {
+	defaultConvertersMutex.RLock()
+	defer defaultConvertersMutex.RUnlock()
+
+	return defaultConvertersVersion
+}
+
+// encodeFuncs is the EncodeFunc registry of an encoder.
+type encodeFuncs map[reflect.Type]EncodeFunc
+
+func (m *encodeFuncs) register(t reflect.Type, f EncodeFunc) {
+	if *m == nil {
+		*m = make(encodeFuncs)
+	}
+	(*m)[t] = f
+}
+
+// lookup returns the EncodeFunc of the type t. The default EncodeFunc is used if not registered.
+func (m encodeFuncs) lookup(t reflect.Type) (EncodeFunc, bool) {
+	if f, ok := m[t]; ok {
+		return f, f != nil
+	}
+
+	defaultConvertersMutex.RLock()
+	defer defaultConvertersMutex.RUnlock()
+
+	f, ok := defaultEncodeFuncs[t]
+	return f, ok
+}
+
+func (m encodeFuncs) has(t reflect.Type) bool {
+	_, ok := m.lookup(t)
+	return ok
+}
+
+// decodeFuncs is the DecodeFunc registry of a decoder.
+type decodeFuncs map[reflect.Type]DecodeFunc
+
+func (m *decodeFuncs) register(t reflect.Type, f DecodeFunc) {
+	if *m == nil {
+		*m = make(decodeFuncs)
+	}
+	(*m)[t] = f
+}
+
+// lookup returns the DecodeFunc of the type t. The default DecodeFunc is used if not registered.
+func (m decodeFuncs) lookup(t reflect.Type) (DecodeFunc, bool) {
+	if f, ok := m[t]; ok {
+		return f, f != nil
+	}
+
+	defaultConvertersMutex.RLock()
+	defer defaultConvertersMutex.RUnlock()
+
+	f, ok := defaultDecodeFuncs[t]
+	return f, ok
+}
+
+func (m decodeFuncs) has(t reflect.Type) bool {
+	_, ok := m.lookup(t)
+	return ok
+}
@@ -0,0 +1,104 @@
+package csvutil_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.nanasi880.dev/x/encoding/csvutil"
+)
+
+// point is a type which cannot have any methods for csvutil (assume it is defined in another package).
+type point struct {
+	X int
+	Y int
+}
+
+func encodePoint(v interface{}) (string, error) {
+	p := v.(point)
+	return fmt.Sprintf("%d:%d", p.X, p.Y), nil
+}
+
+func decodePoint(s string, v interface{}) error {
+	p := v.(*point)
+	_, err := fmt.Sscanf(s, "%d:%d", &p.X, &p.Y)
+	return err
+}
+
+func TestEncoder_Register(t *testing.T) {
+
+	type csvData struct {
+		Name  string `csv:"name"`
+		Point point  `csv:"point"`
+		Ptr   *point `csv:"ptr"`
+	}
+
+	d := []csvData{
+		{Name: "a", Point: point{X: 1, Y: 2}, Ptr: &point{X: 3, Y: 4}},
+		{Name: "b", Point: point{X: 5, Y: 6}, Ptr: nil},
+	}
+
+	out := new(strings.Builder)
+	enc := csvutil.NewEncoder(out)
+	enc.Register(reflect.TypeOf(point{}), encodePoint)
+	if err := enc.Encode(d); err != nil {
+		t.Fatal(err)
+	}
+
+	const want = "name,point,ptr\na,1:2,3:4\nb,5:6,\n"
+	if out.String() != want {
+		t.Fatalf("want: %s got: %s", want, out.String())
+	}
+
+	var decoded []csvData
+	dec := csvutil.NewDecoder(strings.NewReader(out.String()))
+	dec.Register(reflect.TypeOf(point{}), decodePoint)
+	if err := dec.Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, d) {
+		t.Fatal(decoded)
+	}
+}
+
+func TestRegisterEncodeFunc(t *testing.T) {
+
+	type csvData struct {
+		Point point `csv:"point"`
+	}
+
+	csvutil.RegisterEncodeFunc(reflect.TypeOf(point{}), encodePoint)
+	csvutil.RegisterDecodeFunc(reflect.TypeOf(point{}), decodePoint)
+	defer func() {
+		csvutil.RegisterEncodeFunc(reflect.TypeOf(point{}), nil)
+		csvutil.RegisterDecodeFunc(reflect.TypeOf(point{}), nil)
+	}()
+
+	encoded, err := csvutil.MarshalString([]csvData{{Point: point{X: 1, Y: 2}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded != "point\n1:2\n" {
+		t.Fatal(encoded)
+	}
+
+	var decoded []csvData
+	if err := csvutil.UnmarshalString(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded[0].Point != (point{X: 1, Y: 2}) {
+		t.Fatal(decoded)
+	}
+
+	// the default is disabled by registering nil
+	out := new(strings.Builder)
+	enc := csvutil.NewEncoder(out)
+	enc.Register(reflect.TypeOf(point{}), nil)
+	if err := enc.Encode(csvData{Point: point{X: 1, Y: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "point.X,point.Y\n1,2\n" {
+		t.Fatal(out.String())
+	}
+}
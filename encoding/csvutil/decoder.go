@@ -9,13 +9,15 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"go.nanasi880.dev/x/reflect/reflectutil"
 	"go.nanasi880.dev/x/unsafe/unsafeutil"
 )
 
 var (
-	errInvalidDecodeType = fmt.Errorf("pointer of slice of struct (or struct pointer) only")
+	errInvalidDecodeType     = fmt.Errorf("pointer of slice of struct (or struct pointer), Record, map[string]string or map[string]interface{} only")
+	errInvalidDecodeNextType = fmt.Errorf("pointer of struct, Record, map[string]string or map[string]interface{} only")
 )
 
 // Unmarshaler is the interface implemented by types
@@ -40,9 +42,11 @@ func Unmarshal(csv []byte, out interface{}) error {
 // NewDecoder is create csv decoder.
 func NewDecoder(r io.Reader) *Decoder {
 
-	reader := csv.NewReader(r)
+	// the UTF-8 BOM is removed transparently, and the leading lines and the sections are handled before csv.Reader
+	sections := newSectionReader(newBOMReader(r))
+	reader := csv.NewReader(sections)
 
-	return &Decoder{
+	d := &Decoder{
 		Comma:            reader.Comma,
 		Comment:          reader.Comment,
 		FieldsPerRecord:  reader.FieldsPerRecord,
@@ -51,8 +55,33 @@ func NewDecoder(r io.Reader) *Decoder {
 		ReuseRecord:      reader.ReuseRecord,
 		UseHeader:        true,
 		Nil:              "",
-		r:                reader,
+		EmptyCell:        EmptyCellDecode,
+		Lenient:          false,
+		HeaderSeparator:  ".",
+		TimeZone:         nil,
+		UnescapeFormula:  false,
+
+		DisallowUnknownHeaders:   false,
+		DisallowDuplicateHeaders: false,
+		IgnoreHeaderCase:         false,
+		TrimHeaderSpace:          false,
+
+		Workers:   0,
+		ChunkSize: 0,
+		Unordered: false,
+
+		SkipLines: 0,
+		SkipUntil: nil,
+		StopAt:    nil,
+		Sections:  false,
+
+		r:        reader,
+		src:      sections,
+		sections: sections,
 	}
+	sections.d = d
+
+	return d
 }
 
 // Decoder reads CSV values to an input stream.
@@ -65,27 +94,61 @@ type Decoder struct {
 	ReuseRecord      bool
 	UseHeader        bool
 	Nil              string
-	r                *csv.Reader
+	EmptyCell        EmptyCell      // EmptyCell is the policy of the empty cell. The `empty` tag option takes precedence.
+	Lenient          bool           // If true, the records that failed to decode are skipped and all errors are returned as DecodeErrors.
+	HeaderSeparator  string         // HeaderSeparator is the separator between the name of the nested struct and its fields.
+	UnescapeFormula  bool           // If true, the escape added by FormulaProtectionEscape is removed.
+	TimeZone         *time.Location // TimeZone is the default time zone of time.Time. The `tz` tag option takes precedence.
+
+	// The following options are used to validate the header when UseHeader is true.
+	// If the header is invalid, Decode returns *HeaderError before any records are decoded.
+	// Regardless of these options, the columns specified by `required` tag option (e.g. `csv:"name,required"`) must be present in the header.
+	DisallowUnknownHeaders   bool // If true, the columns which are not mapped to any field are rejected.
+	DisallowDuplicateHeaders bool // If true, the columns which appear more than once are rejected.
+	IgnoreHeaderCase         bool // If true, the header is matched case-insensitively.
+	TrimHeaderSpace          bool // If true, the leading and trailing white space of the header is ignored.
+
+	// The following options are used by DecodeParallel and DecodeParallelFunc.
+	Workers   int  // Workers is the number of the goroutines to decode. If 0, runtime.GOMAXPROCS(0) is used.
+	ChunkSize int  // ChunkSize is the approximate size in bytes of the records decoded by a goroutine at once. If 0, 1MiB is used.
+	Unordered bool // If true, DecodeParallelFunc passes the records in the order they are decoded, not in the order of the input.
+
+	// The following options are used to find the records in the reports which have the title lines, the footers or the multiple tables.
+	// The records given to SkipUntil and StopAt are parsed from a line with LazyQuotes, and the comments and the blank lines are not given.
+	SkipLines int                        // SkipLines is the number of the lines skipped at the beginning of the input.
+	SkipUntil func(record []string) bool // If not nil, the records are skipped until SkipUntil returns true at the beginning of each section. The matched record is the header (or the first record).
+	StopAt    func(record []string) bool // If not nil, the section ends at the record which StopAt returns true, e.g. the footer. The matched record is discarded.
+	Sections  bool                       // If true, the blank lines and the records matched by SkipUntil separate the sections. See NextSection.
+
+	r              *csv.Reader
+	src            io.Reader
+	sections       *sectionReader
+	lineOffset     int // lineOffset is the line number of the beginning of the input in the whole input.
+	header         []string
+	fieldIndex     map[int]field
+	plan           *decodePlan
+	fieldIndexType reflect.Type
+	decodeFuncs    decodeFuncs
 }
 
-type decodeElement struct {
-	elem   reflect.Value
-	access reflect.Value
+// Register is register the DecodeFunc of the type t to the Decoder. Register must be called before Decode.
+// The DecodeFunc takes precedence over the default DecodeFunc registered by RegisterDecodeFunc, Unmarshaler, encoding.TextUnmarshaler and the default decoding.
+// If f is nil, the default DecodeFunc of the type t is not used by the Decoder.
+func (d *Decoder) Register(t reflect.Type, f DecodeFunc) {
+	d.decodeFuncs.register(t, f)
 }
 
 // Decode is decodes a slice of a structure from CSV data, CSV data read from the io.Reader specified by NewDecoder.
+// The slice of Record, map[string]string and map[string]interface{} are also supported.
+// The map is keyed by the header, and the type of the value of map[string]interface{} is inferred from the cell.
+// If DecodeNext has already been called, Decode decodes the remaining records.
 func (d *Decoder) Decode(out interface{}) error {
 
 	if out == nil {
 		return fmt.Errorf("nil")
 	}
 
-	d.r.Comma = d.Comma
-	d.r.Comment = d.Comment
-	d.r.FieldsPerRecord = d.FieldsPerRecord
-	d.r.LazyQuotes = d.LazyQuotes
-	d.r.TrimLeadingSpace = d.TrimLeadingSpace
-	d.r.ReuseRecord = d.ReuseRecord
+	d.setupReader()
 
 	sliceElemType, err := d.getValueType(out)
 	if err != nil {
@@ -97,59 +160,236 @@ func (d *Decoder) Decode(out interface{}) error {
 	return d.decodeRows(outSlice, sliceElemType)
 }
 
+// DecodeNext is decodes the next record into a structure, CSV data read from the io.Reader specified by NewDecoder.
+// The out must be a pointer of struct, and it is reset to zero value before decoding.
+// The header is read on the first call, and the mapping of the columns is reused while the type of out is not changed.
+// If the record fails to decode, DecodeNext returns the error and the next call continues from the next record.
+// If there are no more records, DecodeNext returns io.EOF.
+func (d *Decoder) DecodeNext(out interface{}) error {
+
+	if out == nil {
+		return fmt.Errorf("nil")
+	}
+
+	d.setupReader()
+
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errInvalidDecodeNextType
+	}
+	access := rv.Elem()
+	if access.Kind() != reflect.Struct && !isDynamicType(access.Type()) {
+		return errInvalidDecodeNextType
+	}
+
+	plan, err := d.getFieldIndex(access.Type())
+	if err != nil {
+		return err
+	}
+
+	record, err := d.read()
+	if err != nil {
+		return err
+	}
+
+	access.Set(reflect.Zero(access.Type()))
+	return d.decodeRecord(record, access, plan)
+}
+
+func (d *Decoder) setupReader() {
+	d.r.Comma = d.Comma
+	d.r.Comment = d.Comment
+	d.r.FieldsPerRecord = d.FieldsPerRecord
+	d.r.LazyQuotes = d.LazyQuotes
+	d.r.TrimLeadingSpace = d.TrimLeadingSpace
+	d.r.ReuseRecord = d.ReuseRecord
+}
+
 func (d *Decoder) decodeRows(out reflect.Value, elemType reflect.Type) error {
 
-	// csv column index : struct field index
-	var fieldIndex map[int]int
-	if d.UseHeader {
-		header, err := d.r.Read()
-		if err == io.EOF {
-			return nil
-		}
-		if err != nil {
-			return err
-		}
-		fieldIndex = d.getFieldIndexByTag(elemType, header)
-	} else {
-		fieldIndex = d.getFieldIndexByOrder(elemType)
+	// csv column index : struct field
+	plan, err := d.getFieldIndex(elemType)
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
 	}
 
-	result := reflect.MakeSlice(out.Type(), 0, 0)
+	var (
+		result = reflect.MakeSlice(out.Type(), 0, 0)
+		dirty  = false // the element after the end of result is used by the failed record
+		errs   DecodeErrors
+	)
 	for {
-		record, err := d.r.Read()
+		record, err := d.read()
 		if err == io.EOF {
 			out.Set(result)
+			if len(errs) > 0 {
+				return errs
+			}
 			return nil
 		}
 		if err != nil {
 			return err
 		}
 
-		elem := d.allocElem(elemType)
-		for i, raw := range record {
-
-			fi, ok := fieldIndex[i]
-			if !ok {
+		// the record is decoded into the element of result directly to avoid copying
+		n := result.Len()
+		if n == result.Cap() {
+			grown := reflect.MakeSlice(result.Type(), n, 2*n+16)
+			reflect.Copy(grown, result)
+			result = grown
+			dirty = false
+		}
+		result = result.Slice(0, n+1)
+
+		elem := d.elemAt(result.Index(n), dirty)
+		if err := d.decodeRecord(record, elem, plan); err != nil {
+			result = result.Slice(0, n)
+			dirty = true
+			if rowErrs, ok := err.(DecodeErrors); ok {
+				errs = append(errs, rowErrs...)
 				continue
 			}
+			return err
+		}
+		dirty = false
+	}
+}
+
+// elemAt returns the value to decode the record into the element of the slice.
+// If the element is a pointer, the new value is allocated. If dirty is true, the element is reset to zero value.
+func (d *Decoder) elemAt(elem reflect.Value, dirty bool) reflect.Value {
+
+	if elem.Kind() == reflect.Ptr {
+		elem.Set(reflect.New(elem.Type().Elem()))
+		return elem.Elem()
+	}
+
+	if dirty {
+		elem.Set(reflect.Zero(elem.Type()))
+	}
+	return elem
+}
 
-			field := elem.access.Field(fi)
-			if err := d.decodeValue(raw, field); err != nil {
-				return err
+// decodeRecord is decodes the record into access.
+// If the Decoder is lenient, all errors of the record are returned as DecodeErrors, otherwise the first error is returned as *DecodeError.
+func (d *Decoder) decodeRecord(record []string, access reflect.Value, plan *decodePlan) error {
+
+	if isDynamicType(access.Type()) {
+		err := d.decodeDynamicRecord(record, access)
+		if decodeErr, ok := err.(*DecodeError); ok && d.Lenient {
+			return DecodeErrors{decodeErr}
+		}
+		return err
+	}
+
+	var errs DecodeErrors
+	for i, raw := range record {
+
+		if i >= len(plan.columns) {
+			break
+		}
+		c := plan.columns[i]
+		if c == nil {
+			continue
+		}
+
+		var (
+			f   = &c.field
+			fv  = fieldByIndexAlloc(access, f.index)
+			err error
+		)
+		if c.fast {
+			err = d.decodeBasic(raw, fv, f)
+		} else {
+			err = d.decodeField(raw, fv, f)
+		}
+		if err != nil {
+			decodeErr := d.newDecodeError(i, raw, f.path, err)
+			if !d.Lenient {
+				return decodeErr
 			}
+			errs = append(errs, decodeErr)
 		}
+	}
 
-		result = reflect.Append(result, elem.elem)
+	if len(errs) > 0 {
+		return errs
 	}
+	return nil
 }
 
-func (d *Decoder) decodeValue(raw string, rv reflect.Value) error {
+func (d *Decoder) newDecodeError(column int, raw string, fieldName string, err error) *DecodeError {
 
-	if rv.Kind() == reflect.Ptr && raw == d.Nil {
-		if !rv.IsNil() {
-			rv.Set(reflect.Zero(rv.Type()))
+	line, _ := d.r.FieldPos(column)
+	line += d.lineOffset
+
+	var header string
+	if d.UseHeader && column < len(d.header) {
+		header = d.header[column]
+	}
+
+	return &DecodeError{
+		Line:   line,
+		Column: column,
+		Header: header,
+		Field:  fieldName,
+		Value:  raw,
+		Err:    err,
+	}
+}
+
+// getFieldIndex returns the plan of the csv column index to struct field index mapping of t.
+// The header is read from the input only once, and the plan is cached until the type is changed.
+func (d *Decoder) getFieldIndex(t reflect.Type) (*decodePlan, error) {
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if d.fieldIndexType == t {
+		return d.plan, nil
+	}
+
+	if d.UseHeader && d.header == nil {
+		header, err := d.read()
+		if err != nil {
+			return nil, err
 		}
-		return nil
+		// the record may be reused by csv.Reader
+		d.header = append(make([]string, 0, len(header)), header...)
+	}
+
+	if isDynamicType(t) {
+		// the dynamic types are decoded by the header
+		d.fieldIndex = nil
+	} else if d.UseHeader {
+		fieldIndex, err := d.getFieldIndexByTag(t, d.header)
+		if err != nil {
+			return nil, err
+		}
+		d.fieldIndex = fieldIndex
+	} else {
+		fieldIndex, err := d.getFieldIndexByOrder(t)
+		if err != nil {
+			return nil, err
+		}
+		d.fieldIndex = fieldIndex
+	}
+	d.fieldIndexType = t
+	d.plan = d.newDecodePlan(d.fieldIndex)
+
+	return d.plan, nil
+}
+
+func (d *Decoder) decodeValue(raw string, rv reflect.Value, f *field) error {
+
+	nullable := rv.Kind() == reflect.Ptr || (isNullableType(rv.Type()) && !d.decodeFuncs.has(rv.Type()))
+	raw, ok, err := d.decodeEmpty(raw, rv, f, nullable)
+	if !ok {
+		return err
 	}
 
 	rawBytes := unsafeutil.StringToBytes(raw)
@@ -160,21 +400,85 @@ func (d *Decoder) decodeValue(raw string, rv reflect.Value) error {
 		access = rv.Elem()
 	}
 
-	if i, ok := rv.Interface().(Unmarshaler); ok {
+	if decode, ok := d.decodeFuncs.lookup(access.Type()); ok {
+		return decode(raw, access.Addr().Interface())
+	}
+
+	if isNullableType(access.Type()) {
+		return d.decodeNullable(raw, access, f)
+	}
+
+	if f.format.isTime(access.Type(), d.TimeZone) {
+		v, err := f.format.parseTime(raw, d.TimeZone)
+		if err != nil {
+			return err
+		}
+		access.Set(reflect.ValueOf(v))
+		return nil
+	}
+
+	if access.Type() == durationType {
+		v, err := parseDuration(raw)
+		if err != nil {
+			return err
+		}
+		access.SetInt(int64(v))
+		return nil
+	}
+
+	// the methods may have a pointer receiver
+	receiver := access
+	if access.CanAddr() {
+		receiver = access.Addr()
+	}
+	if i, ok := receiver.Interface().(Unmarshaler); ok {
 		return i.UnmarshalCSV(rawBytes)
 	}
-	if i, ok := rv.Interface().(encoding.TextUnmarshaler); ok {
+	if i, ok := receiver.Interface().(encoding.TextUnmarshaler); ok {
 		return i.UnmarshalText(rawBytes)
 	}
 
+	return d.decodeKind(raw, access, f)
+}
+
+// decodeEmpty applies the `default` tag option, nil and the policy of the empty cell.
+// If the cell is decoded, or an error occurs, the second return value is false.
+// Otherwise, the cell (may be replaced by the default) is returned to decode as the value.
+func (d *Decoder) decodeEmpty(raw string, rv reflect.Value, f *field, nullable bool) (string, bool, error) {
+
+	if raw == "" && f.format.defaultValue != nil {
+		raw = *f.format.defaultValue
+	}
+
+	if nullable && raw == f.format.nilString(d.Nil) {
+		rv.Set(reflect.Zero(rv.Type()))
+		return raw, false, nil
+	}
+
+	if raw == "" {
+		switch f.format.emptyCellPolicy(d.EmptyCell) {
+		case EmptyCellZero:
+			rv.Set(reflect.Zero(rv.Type()))
+			return raw, false, nil
+		case EmptyCellError:
+			return raw, false, errEmptyCell
+		}
+	}
+
+	return raw, true, nil
+}
+
+// decodeKind is decodes the cell into the value by the kind of the value.
+func (d *Decoder) decodeKind(raw string, access reflect.Value, f *field) error {
+
 	switch kind := access.Kind(); kind {
 
 	case reflect.Bool:
-		v, err := strconv.ParseBool(raw)
+		v, err := f.format.parseBool(raw)
 		if err != nil {
 			return err
 		}
-		access.Set(reflect.ValueOf(v))
+		access.SetBool(v)
 		return nil
 
 	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
@@ -198,7 +502,7 @@ func (d *Decoder) decodeValue(raw string, rv reflect.Value) error {
 		if err != nil {
 			return err
 		}
-		access.Set(reflect.ValueOf(float32(v)))
+		access.SetFloat(v)
 		return nil
 
 	case reflect.Float64:
@@ -206,7 +510,7 @@ func (d *Decoder) decodeValue(raw string, rv reflect.Value) error {
 		if err != nil {
 			return err
 		}
-		access.Set(reflect.ValueOf(v))
+		access.SetFloat(v)
 		return nil
 
 	case reflect.Complex64:
@@ -226,77 +530,135 @@ func (d *Decoder) decodeValue(raw string, rv reflect.Value) error {
 		return nil
 
 	case reflect.String:
-		access.Set(reflect.ValueOf(raw))
+		access.SetString(raw)
 		return nil
 
 	default:
-		return fmt.Errorf("cannot decode")
+		return fmt.Errorf("unsupported type: %s", access.Type().String())
 	}
 }
 
-func (d *Decoder) getFieldIndexByOrder(t reflect.Type) map[int]int {
+func (d *Decoder) getFieldIndexByOrder(t reflect.Type) (map[int]field, error) {
 
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
+	fields, err := d.typeFields(t)
+	if err != nil {
+		return nil, err
 	}
 
-	var (
-		numField = t.NumField()
-		result   = make(map[int]int)
-		j        = 0
-	)
-	for i := 0; i < numField; i++ {
+	fields, err = orderFields(fields)
+	if err != nil {
+		return nil, err
+	}
 
-		field := t.Field(i)
-		if tag, ok := field.Tag.Lookup("csv"); ok {
-			if tag == "-" {
-				continue
-			}
+	result := make(map[int]field, len(fields))
+	for i, f := range fields {
+		if f.kind == fieldKindMapPrefix || f.kind == fieldKindPadding {
+			// the columns cannot be determined without the header
+			continue
 		}
-
-		result[j] = i
-		j++
+		result[i] = f
 	}
 
-	return result
+	return result, nil
 }
 
-func (d *Decoder) getFieldIndexByTag(t reflect.Type, header []string) map[int]int {
+func (d *Decoder) getFieldIndexByTag(t reflect.Type, header []string) (map[int]field, error) {
 
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
+	normalized := make([]string, len(header))
+	for i, name := range header {
+		normalized[i] = d.normalizeHeader(name)
+	}
+
+	fields, err := d.typeFields(t)
+	if err != nil {
+		return nil, err
 	}
 
 	var (
-		numField = t.NumField()
-		result   = make(map[int]int)
-		last     = make(map[string]int)
+		result = make(map[int]field, len(fields))
+		last   = make(map[string]int)
+		errs   HeaderError
 	)
-	for i := 0; i < numField; i++ {
+	for _, f := range fields {
 
-		field := t.Field(i)
-		name := field.Name
-		if tag, ok := field.Tag.Lookup("csv"); ok {
-			if tag == "-" {
-				continue
-			}
-			name = tag
+		if f.kind == fieldKindMapPrefix {
+			continue
 		}
 
+		name := d.normalizeHeader(f.name)
+
 		j, ok := last[name]
 		if ok {
 			j++
 		}
-		for ; j < len(header); j++ {
-			if header[j] == name {
-				result[j] = i
+		found := false
+		for ; j < len(normalized); j++ {
+			if normalized[j] == name {
+				result[j] = f
 				last[name] = j
+				found = true
 				break
 			}
 		}
+
+		if !found && f.required {
+			errs.Missing = append(errs.Missing, f.name)
+		}
+	}
+
+	// collect the remaining columns which have the prefix into the map
+	for _, f := range fields {
+		if f.kind != fieldKindMapPrefix {
+			continue
+		}
+		prefix := d.normalizeHeader(f.name)
+		for j, name := range normalized {
+			if _, ok := result[j]; ok || !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+				continue
+			}
+			key := header[j]
+			if d.TrimHeaderSpace {
+				key = strings.TrimSpace(key)
+			}
+			element := f
+			element.mapKey = key[len(prefix):]
+			result[j] = element
+		}
 	}
 
-	return result
+	if d.DisallowUnknownHeaders {
+		for i, name := range header {
+			if _, ok := result[i]; !ok {
+				errs.Unknown = append(errs.Unknown, name)
+			}
+		}
+	}
+
+	if d.DisallowDuplicateHeaders {
+		seen := make(map[string]int, len(normalized))
+		for i, name := range normalized {
+			seen[name]++
+			if seen[name] == 2 {
+				errs.Duplicate = append(errs.Duplicate, header[i])
+			}
+		}
+	}
+
+	if len(errs.Missing) > 0 || len(errs.Unknown) > 0 || len(errs.Duplicate) > 0 {
+		return nil, &errs
+	}
+
+	return result, nil
+}
+
+func (d *Decoder) normalizeHeader(name string) string {
+	if d.TrimHeaderSpace {
+		name = strings.TrimSpace(name)
+	}
+	if d.IgnoreHeaderCase {
+		name = strings.ToLower(name)
+	}
+	return name
 }
 
 func (d *Decoder) getValueType(v interface{}) (reflect.Type, error) {
@@ -321,6 +683,9 @@ func (d *Decoder) getValueType(v interface{}) (reflect.Type, error) {
 }
 
 func (d *Decoder) validateValueType(t reflect.Type) error {
+	if isDynamicType(t) || (t.Kind() == reflect.Ptr && t.Elem() == recordType) {
+		return nil
+	}
 	switch t.Kind() {
 	case reflect.Struct:
 		return nil
@@ -334,23 +699,6 @@ func (d *Decoder) validateValueType(t reflect.Type) error {
 	}
 }
 
-func (d *Decoder) allocElem(t reflect.Type) decodeElement {
-
-	if t.Kind() == reflect.Ptr {
-		rv := reflect.New(t.Elem())
-		return decodeElement{
-			elem:   rv,
-			access: rv.Elem(),
-		}
-	}
-
-	rv := reflect.New(t).Elem()
-	return decodeElement{
-		elem:   rv,
-		access: rv,
-	}
-}
-
 func (d *Decoder) parseComplex(raw string) (string, string, error) {
 
 	// (1+2i)
@@ -2,8 +2,11 @@ package csvutil_test
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"strings"
 	"testing"
 
 	"go.nanasi880.dev/x/encoding/csvutil"
@@ -124,3 +127,262 @@ func TestDecoder_Decode(t *testing.T) {
 		})
 	}
 }
+
+func ExampleDecoder_DecodeNext() {
+	const csvString = `Name,Age
+Bob,18
+Alice,20`
+
+	type csvData struct {
+		Name string
+		Age  int
+	}
+
+	dec := csvutil.NewDecoder(strings.NewReader(csvString))
+	for {
+		var row csvData
+		err := dec.DecodeNext(&row)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(row)
+	}
+	// Output:
+	// {Bob 18}
+	// {Alice 20}
+}
+
+func TestDecoder_DecodeNext(t *testing.T) {
+
+	type csvData struct {
+		Name string
+		Age  *int
+	}
+
+	const csvString = "Name,Age\nBob,18\nAlice,\nCarol,30\n"
+
+	dec := csvutil.NewDecoder(strings.NewReader(csvString))
+	dec.ReuseRecord = true
+
+	var row csvData
+	if err := dec.DecodeNext(&row); err != nil {
+		t.Fatal(err)
+	}
+	if row.Name != "Bob" || row.Age == nil || *row.Age != 18 {
+		t.Fatal(row)
+	}
+
+	// the previous value must not be left
+	if err := dec.DecodeNext(&row); err != nil {
+		t.Fatal(err)
+	}
+	if row.Name != "Alice" || row.Age != nil {
+		t.Fatal(row)
+	}
+
+	// the remaining records can be decoded by Decode
+	var rest []csvData
+	if err := dec.Decode(&rest); err != nil {
+		t.Fatal(err)
+	}
+	if len(rest) != 1 || rest[0].Name != "Carol" || *rest[0].Age != 30 {
+		t.Fatal(rest)
+	}
+
+	if err := dec.DecodeNext(&row); err != io.EOF {
+		t.Fatal(err)
+	}
+
+	if err := csvutil.NewDecoder(strings.NewReader("")).DecodeNext(&row); err != io.EOF {
+		t.Fatal(err)
+	}
+	if err := csvutil.NewDecoder(strings.NewReader(csvString)).DecodeNext(row); err == nil {
+		t.Fatal("non-pointer must be rejected")
+	}
+}
+
+func TestDecoder_DecodeError(t *testing.T) {
+
+	type csvData struct {
+		Name string
+		Age  int `csv:"age"`
+		Rate float64
+	}
+
+	const csvString = "Name,age,Rate\nBob,18,0.5\nAlice,x,0.5\nCarol,20,y\nDave,z,w\n"
+
+	t.Run("Strict", func(t *testing.T) {
+		var out []csvData
+		err := csvutil.UnmarshalString(csvString, &out)
+
+		var decodeErr *csvutil.DecodeError
+		if !errors.As(err, &decodeErr) {
+			t.Fatal(err)
+		}
+		want := csvutil.DecodeError{
+			Line:   3,
+			Column: 1,
+			Header: "age",
+			Field:  "Age",
+			Value:  "x",
+		}
+		decodeErr.Err = nil
+		if *decodeErr != want {
+			t.Fatal(*decodeErr)
+		}
+	})
+
+	t.Run("Lenient", func(t *testing.T) {
+		dec := csvutil.NewDecoder(strings.NewReader(csvString))
+		dec.Lenient = true
+
+		var out []csvData
+		err := dec.Decode(&out)
+
+		var decodeErrs csvutil.DecodeErrors
+		if !errors.As(err, &decodeErrs) {
+			t.Fatal(err)
+		}
+		if len(out) != 1 || out[0].Name != "Bob" {
+			t.Fatal(out)
+		}
+
+		type position struct {
+			line   int
+			column int
+			field  string
+		}
+		want := []position{{3, 1, "Age"}, {4, 2, "Rate"}, {5, 1, "Age"}, {5, 2, "Rate"}}
+		if len(decodeErrs) != len(want) {
+			t.Fatal(decodeErrs)
+		}
+		for i, e := range decodeErrs {
+			got := position{e.Line, e.Column, e.Field}
+			if got != want[i] {
+				t.Fatal(i, got)
+			}
+		}
+	})
+}
+
+func TestDecoder_Decode_Nested(t *testing.T) {
+
+	type Address struct {
+		City string `csv:"city"`
+		Zip  string `csv:"zip"`
+	}
+	type Base struct {
+		ID int `csv:"id"`
+	}
+	type csvData struct {
+		*Base
+		Name    string   `csv:"name"`
+		Address Address  `csv:"address"`
+		Billing *Address `csv:"billing"`
+	}
+
+	const csvString = "billing.city,name,id,address.zip,address.city\n" +
+		"Osaka,Bob,1,100,Tokyo\n"
+
+	var out []csvData
+	if err := csvutil.UnmarshalString(csvString, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []csvData{
+		{Base: &Base{ID: 1}, Name: "Bob", Address: Address{City: "Tokyo", Zip: "100"}, Billing: &Address{City: "Osaka"}},
+	}
+	if !reflect.DeepEqual(out, want) {
+		t.Fatal(out)
+	}
+}
+
+func TestDecoder_Decode_HeaderValidation(t *testing.T) {
+
+	type csvData struct {
+		ID   int    `csv:"id,required"`
+		Name string `csv:"name,required"`
+		Memo string `csv:"memo"`
+	}
+
+	data := []struct {
+		Name      string
+		CSV       string
+		Setup     func(dec *csvutil.Decoder)
+		WantError *csvutil.HeaderError
+		Want      []csvData
+	}{
+		{
+			Name:  "Valid",
+			CSV:   "name,extra,id\nBob,x,1\n",
+			Setup: func(dec *csvutil.Decoder) {},
+			Want:  []csvData{{ID: 1, Name: "Bob"}},
+		},
+		{
+			Name:      "Missing",
+			CSV:       "name,memo\nBob,x\n",
+			Setup:     func(dec *csvutil.Decoder) {},
+			WantError: &csvutil.HeaderError{Missing: []string{"id"}},
+		},
+		{
+			Name: "Unknown",
+			CSV:  "id,name,extra,other\n1,Bob,x,y\n",
+			Setup: func(dec *csvutil.Decoder) {
+				dec.DisallowUnknownHeaders = true
+			},
+			WantError: &csvutil.HeaderError{Unknown: []string{"extra", "other"}},
+		},
+		{
+			Name: "Duplicate",
+			CSV:  "id,name,memo,memo\n1,Bob,x,y\n",
+			Setup: func(dec *csvutil.Decoder) {
+				dec.DisallowDuplicateHeaders = true
+			},
+			WantError: &csvutil.HeaderError{Duplicate: []string{"memo"}},
+		},
+		{
+			Name: "CaseAndSpace",
+			CSV:  " ID ,Name,MEMO\n1,Bob,x\n",
+			Setup: func(dec *csvutil.Decoder) {
+				dec.IgnoreHeaderCase = true
+				dec.TrimHeaderSpace = true
+				dec.DisallowUnknownHeaders = true
+			},
+			Want: []csvData{{ID: 1, Name: "Bob", Memo: "x"}},
+		},
+	}
+
+	for _, data := range data {
+		data := data
+		t.Run(data.Name, func(t *testing.T) {
+
+			dec := csvutil.NewDecoder(strings.NewReader(data.CSV))
+			data.Setup(dec)
+
+			var out []csvData
+			err := dec.Decode(&out)
+			if data.WantError != nil {
+				var headerErr *csvutil.HeaderError
+				if !errors.As(err, &headerErr) {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(headerErr, data.WantError) {
+					t.Fatal(headerErr)
+				}
+				if out != nil {
+					t.Fatal(out)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(out, data.Want) {
+				t.Fatal(out)
+			}
+		})
+	}
+}
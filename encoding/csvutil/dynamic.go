@@ -0,0 +1,242 @@
+package csvutil
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	recordType    = reflect.TypeOf(Record{})
+	interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+	// inferTimeLayouts is the layouts of time.Time tried by the type inference.
+	inferTimeLayouts = []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05",
+		"2006-01-02",
+	}
+)
+
+// Record is a CSV record which keeps the order of the columns.
+// Decoder can decode CSV data into []Record, and Encoder can encode []Record into CSV data.
+type Record struct {
+	Header []string // Header is the header of the CSV. It is shared between the records decoded by the same Decoder. If the header is not used, Header is nil.
+	Values []string // Values is the values of the record.
+}
+
+// Lookup returns the value of the column named name, and whether the column exists.
+func (r Record) Lookup(name string) (string, bool) {
+	for i, h := range r.Header {
+		if h == name && i < len(r.Values) {
+			return r.Values[i], true
+		}
+	}
+	return "", false
+}
+
+// Get returns the value of the column named name. If the column does not exist, Get returns empty string.
+func (r Record) Get(name string) string {
+	v, _ := r.Lookup(name)
+	return v
+}
+
+// isDynamicType returns whether t is decoded by the header name instead of struct fields.
+// The dynamic types are Record, map[string]string and map[string]interface{}.
+func isDynamicType(t reflect.Type) bool {
+	if t == recordType {
+		return true
+	}
+	if t.Kind() != reflect.Map || t.Key().Kind() != reflect.String {
+		return false
+	}
+	return t.Elem().Kind() == reflect.String || t.Elem() == interfaceType
+}
+
+// decodeDynamicRecord is decodes the record into Record or map.
+func (d *Decoder) decodeDynamicRecord(record []string, access reflect.Value) error {
+
+	if access.Type() == recordType {
+		access.Set(reflect.ValueOf(Record{
+			Header: d.header,
+			Values: append(make([]string, 0, len(record)), record...),
+		}))
+		return nil
+	}
+
+	if !d.UseHeader {
+		return fmt.Errorf("the header is required to decode into %s", access.Type().String())
+	}
+
+	m := reflect.MakeMapWithSize(access.Type(), len(record))
+	for i, raw := range record {
+		if i >= len(d.header) {
+			break
+		}
+
+		key := reflect.ValueOf(d.header[i]).Convert(access.Type().Key())
+		if access.Type().Elem() == interfaceType {
+			v, err := d.inferValue(raw)
+			if err != nil {
+				return d.newDecodeError(i, raw, d.header[i], err)
+			}
+			if v == nil {
+				m.SetMapIndex(key, reflect.Zero(interfaceType))
+			} else {
+				m.SetMapIndex(key, reflect.ValueOf(v))
+			}
+		} else {
+			m.SetMapIndex(key, reflect.ValueOf(raw).Convert(access.Type().Elem()))
+		}
+	}
+	access.Set(m)
+
+	return nil
+}
+
+// hasLeadingZero returns whether s is the digits which begin with the redundant zero, e.g. 00123 and -01.
+// The zero itself and the fraction such as 0.5 are not.
+func hasLeadingZero(s string) bool {
+	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		s = s[1:]
+	}
+	return len(s) > 1 && s[0] == '0' && s[1] >= '0' && s[1] <= '9'
+}
+
+// inferValue returns the value of the cell with the inferred type.
+// The value is nil, int64, float64, bool, time.Time or string, in order of priority.
+func (d *Decoder) inferValue(raw string) (interface{}, error) {
+
+	if raw == d.Nil {
+		return nil, nil
+	}
+
+	// the numbers with the leading zeros such as zip codes are treated as the strings, not to lose the zeros
+	if !hasLeadingZero(raw) {
+		if v, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return v, nil
+		}
+		if v, err := strconv.ParseFloat(raw, 64); err == nil && !math.IsNaN(v) && !math.IsInf(v, 0) {
+			// ParseFloat accepts the words such as NaN and Inf, which are treated as the strings
+			return v, nil
+		}
+	}
+	switch strings.ToLower(raw) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+
+	loc := d.TimeZone
+	if loc == nil {
+		loc = time.UTC
+	}
+	for _, layout := range inferTimeLayouts {
+		if v, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			return v, nil
+		}
+	}
+
+	return raw, nil
+}
+
+// mapColumns returns the columns of the map.
+// If Encoder.Columns is nil, the keys of the map are used in sorted order.
+func (e *Encoder) mapColumns(v reflect.Value) []field {
+
+	columns := e.Columns
+	if columns == nil {
+		for _, key := range v.MapKeys() {
+			columns = append(columns, key.String())
+		}
+		sort.Strings(columns)
+	}
+
+	return namedFields(columns)
+}
+
+// recordColumns returns the columns of the Record.
+// If Encoder.Columns is nil, the header of the Record is used.
+func (e *Encoder) recordColumns(r Record) []field {
+	if e.Columns == nil {
+		return namedFields(r.Header)
+	}
+	return namedFields(e.Columns)
+}
+
+func namedFields(columns []string) []field {
+	fields := make([]field, 0, len(columns))
+	for _, name := range columns {
+		fields = append(fields, field{
+			name: name,
+			path: name,
+		})
+	}
+	return fields
+}
+
+func (e *Encoder) encodeMap(v reflect.Value) error {
+
+	if v.Type().Key().Kind() != reflect.String {
+		return errInvalidType
+	}
+
+	if err := e.prepareType(v.Type(), func() ([]field, error) {
+		return e.mapColumns(v), nil
+	}); err != nil {
+		return err
+	}
+
+	values := make([]string, 0, len(e.fieldsCache))
+	for i := range e.fieldsCache {
+		f := &e.fieldsCache[i]
+
+		mv := v.MapIndex(reflect.ValueOf(f.name).Convert(v.Type().Key()))
+		if mv.IsValid() && mv.Kind() == reflect.Interface {
+			mv = mv.Elem()
+		}
+		if !mv.IsValid() {
+			// the key does not exist or the value is nil interface
+			values = append(values, e.Nil)
+			continue
+		}
+
+		encoded, err := e.encodeValue(mv, f)
+		if err != nil {
+			return err
+		}
+		values = append(values, encoded)
+	}
+
+	return e.write(values)
+}
+
+func (e *Encoder) encodeRecord(r Record) error {
+
+	if err := e.prepareType(recordType, func() ([]field, error) {
+		return e.recordColumns(r), nil
+	}); err != nil {
+		return err
+	}
+
+	if e.Columns == nil {
+		return e.write(r.Values)
+	}
+
+	values := make([]string, 0, len(e.Columns))
+	for _, name := range e.Columns {
+		v, ok := r.Lookup(name)
+		if !ok {
+			v = e.Nil
+		}
+		values = append(values, v)
+	}
+
+	return e.write(values)
+}
@@ -0,0 +1,183 @@
+package csvutil_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.nanasi880.dev/x/encoding/csvutil"
+)
+
+func ExampleRecord() {
+	const csvString = `Name,Age
+Bob,18
+Alice,20`
+
+	var records []csvutil.Record
+	if err := csvutil.UnmarshalString(csvString, &records); err != nil {
+		panic(err)
+	}
+
+	for _, r := range records {
+		fmt.Println(r.Get("Name"), r.Get("Age"))
+	}
+	// Output:
+	// Bob 18
+	// Alice 20
+}
+
+func TestDecoder_Decode_Map(t *testing.T) {
+
+	const csvString = "name,age,rate,active,born,memo\n" +
+		"Bob,18,0.5,true,2003-04-01,\n"
+
+	t.Run("StringMap", func(t *testing.T) {
+		var out []map[string]string
+		if err := csvutil.UnmarshalString(csvString, &out); err != nil {
+			t.Fatal(err)
+		}
+		want := []map[string]string{
+			{"name": "Bob", "age": "18", "rate": "0.5", "active": "true", "born": "2003-04-01", "memo": ""},
+		}
+		if !reflect.DeepEqual(out, want) {
+			t.Fatal(out)
+		}
+	})
+
+	t.Run("InterfaceMap", func(t *testing.T) {
+		var out []map[string]interface{}
+		if err := csvutil.UnmarshalString(csvString, &out); err != nil {
+			t.Fatal(err)
+		}
+		want := []map[string]interface{}{
+			{
+				"name":   "Bob",
+				"age":    int64(18),
+				"rate":   0.5,
+				"active": true,
+				"born":   time.Date(2003, 4, 1, 0, 0, 0, 0, time.UTC),
+				"memo":   nil,
+			},
+		}
+		if !reflect.DeepEqual(out, want) {
+			t.Fatal(out)
+		}
+	})
+
+	t.Run("NonFinite", func(t *testing.T) {
+		var out []map[string]interface{}
+		if err := csvutil.UnmarshalString("a,b,c,d\nNan,Inf,-infinity,1e3\n", &out); err != nil {
+			t.Fatal(err)
+		}
+		want := []map[string]interface{}{
+			{"a": "Nan", "b": "Inf", "c": "-infinity", "d": 1000.0},
+		}
+		if !reflect.DeepEqual(out, want) {
+			t.Fatal(out)
+		}
+	})
+
+	t.Run("LeadingZero", func(t *testing.T) {
+		var out []map[string]interface{}
+		if err := csvutil.UnmarshalString("a,b,c,d,e,f\n00123,0,-0,0.5,-007,0010.5\n", &out); err != nil {
+			t.Fatal(err)
+		}
+		want := []map[string]interface{}{
+			{"a": "00123", "b": int64(0), "c": int64(0), "d": 0.5, "e": "-007", "f": "0010.5"},
+		}
+		if !reflect.DeepEqual(out, want) {
+			t.Fatal(out)
+		}
+	})
+
+	t.Run("DecodeNext", func(t *testing.T) {
+		dec := csvutil.NewDecoder(strings.NewReader(csvString))
+		var record csvutil.Record
+		if err := dec.DecodeNext(&record); err != nil {
+			t.Fatal(err)
+		}
+		if v, ok := record.Lookup("age"); !ok || v != "18" {
+			t.Fatal(record)
+		}
+		if _, ok := record.Lookup("unknown"); ok {
+			t.Fatal(record)
+		}
+	})
+
+	t.Run("NoHeader", func(t *testing.T) {
+		dec := csvutil.NewDecoder(strings.NewReader("a,b\n"))
+		dec.UseHeader = false
+
+		var records []csvutil.Record
+		if err := dec.Decode(&records); err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 1 || records[0].Header != nil || !reflect.DeepEqual(records[0].Values, []string{"a", "b"}) {
+			t.Fatal(records)
+		}
+
+		dec = csvutil.NewDecoder(strings.NewReader("a,b\n"))
+		dec.UseHeader = false
+		var maps []map[string]string
+		if err := dec.Decode(&maps); err == nil {
+			t.Fatal(maps)
+		}
+	})
+}
+
+func TestEncoder_Encode_Map(t *testing.T) {
+
+	t.Run("SortedKeys", func(t *testing.T) {
+		encoded, err := csvutil.MarshalString([]map[string]interface{}{
+			{"b": 1, "a": "x", "c": nil},
+			{"b": 2.5, "a": time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		const want = "a,b,c\nx,1,\n2021-01-02T00:00:00Z,2.5,\n"
+		if encoded != want {
+			t.Fatalf("want: %s got: %s", want, encoded)
+		}
+	})
+
+	t.Run("Columns", func(t *testing.T) {
+		out := new(strings.Builder)
+		enc := csvutil.NewEncoder(out)
+		enc.Columns = []string{"name", "age"}
+		if err := enc.Encode([]map[string]string{{"age": "18", "name": "Bob", "ignored": "x"}}); err != nil {
+			t.Fatal(err)
+		}
+		const want = "name,age\nBob,18\n"
+		if out.String() != want {
+			t.Fatalf("want: %s got: %s", want, out.String())
+		}
+	})
+
+	t.Run("Record", func(t *testing.T) {
+		records := []csvutil.Record{
+			{Header: []string{"name", "age"}, Values: []string{"Bob", "18"}},
+			{Header: []string{"name", "age"}, Values: []string{"Alice", "20"}},
+		}
+
+		encoded, err := csvutil.MarshalString(records)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if encoded != "name,age\nBob,18\nAlice,20\n" {
+			t.Fatal(encoded)
+		}
+
+		out := new(strings.Builder)
+		enc := csvutil.NewEncoder(out)
+		enc.Columns = []string{"age", "name"}
+		if err := enc.Encode(records); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != "age,name\n18,Bob\n20,Alice\n" {
+			t.Fatal(out.String())
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package csvutil
 
 import (
+	"bufio"
 	"bytes"
 	"encoding"
 	"encoding/csv"
@@ -8,13 +9,14 @@ import (
 	"io"
 	"reflect"
 	"strings"
+	"time"
 
 	"go.nanasi880.dev/x/reflect/reflectutil"
 	"go.nanasi880.dev/x/unsafe/unsafeutil"
 )
 
 var (
-	errInvalidType         = fmt.Errorf("slice of struct or array of struct or struct or pointer of struct only")
+	errInvalidType         = fmt.Errorf("struct, pointer of struct, map of string key, Record, or slice or array of them only")
 	wellKnownEncodingKinds = []reflect.Kind{
 		reflect.Bool,
 		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
@@ -54,31 +56,63 @@ func Marshal(v interface{}) ([]byte, error) {
 // NewEncoder is create csv encoder.
 func NewEncoder(w io.Writer) *Encoder {
 
-	writer := csv.NewWriter(w)
+	// csv.Writer shares the bufio.Writer, so that the BOM and the quoted records are written in order.
+	bw := bufio.NewWriter(w)
+	writer := csv.NewWriter(bw)
 
 	return &Encoder{
-		Comma:          writer.Comma,
-		UseCRLF:        writer.UseCRLF,
-		UseHeader:      true,
-		Nil:            "",
-		w:              writer,
-		alreadyWritten: false,
-		typeCache:      nil,
+		Comma:             writer.Comma,
+		UseCRLF:           writer.UseCRLF,
+		UseHeader:         true,
+		Nil:               "",
+		HeaderSeparator:   ".",
+		TimeZone:          nil,
+		Columns:           nil,
+		FormulaProtection: FormulaProtectionNone,
+		UseBOM:            false,
+		QuoteAll:          false,
+		w:                 writer,
+		bw:                bw,
+		bomWritten:        false,
+		alreadyWritten:    false,
+		typeCache:         nil,
+		fieldsCache:       nil,
+		plan:              nil,
+		encodeFuncs:       nil,
 	}
 }
 
 // An Encoder writes CSV values to an output stream.
 type Encoder struct {
-	Comma          rune
-	UseCRLF        bool
-	UseHeader      bool
-	Nil            string
-	w              *csv.Writer
-	alreadyWritten bool
-	typeCache      reflect.Type
+	Comma             rune
+	UseCRLF           bool
+	UseHeader         bool
+	Nil               string
+	HeaderSeparator   string            // HeaderSeparator is the separator between the name of the nested struct and its fields.
+	TimeZone          *time.Location    // TimeZone is the default time zone of time.Time. The `tz` tag option takes precedence.
+	FormulaProtection FormulaProtection // FormulaProtection is how to treat the cells which may be interpreted as a formula by spreadsheet applications.
+	Columns           []string          // Columns is the header names of the columns to encode in order. If nil, the columns of the struct are ordered by the index option and the declaration, the sorted keys of the first map or the header of the first Record are used.
+	UseBOM            bool              // If true, the UTF-8 BOM is written at the beginning.
+	QuoteAll          bool              // If true, all fields are quoted.
+	w                 *csv.Writer
+	bw                *bufio.Writer
+	bomWritten        bool
+	alreadyWritten    bool
+	typeCache         reflect.Type
+	fieldsCache       []field
+	plan              *encodePlan
+	encodeFuncs       encodeFuncs
+}
+
+// Register is register the EncodeFunc of the type t to the Encoder. Register must be called before Encode.
+// The EncodeFunc takes precedence over the default EncodeFunc registered by RegisterEncodeFunc, Marshaler, encoding.TextMarshaler and the default encoding.
+// If f is nil, the default EncodeFunc of the type t is not used by the Encoder.
+func (e *Encoder) Register(t reflect.Type, f EncodeFunc) {
+	e.encodeFuncs.register(t, f)
 }
 
 // Encode is encodes a structure or slice of a structure into CSV data and output to the io.Writer specified by NewEncoder.
+// A map of string key and Record are also encoded in the order of the Columns.
 func (e *Encoder) Encode(v interface{}) (err error) {
 
 	if v == nil {
@@ -103,6 +137,8 @@ func (e *Encoder) Encode(v interface{}) (err error) {
 		return e.encodePtr(rv)
 	case reflect.Struct:
 		return e.encodeStruct(rv)
+	case reflect.Map:
+		return e.encodeMap(rv)
 	default:
 		return errInvalidType
 	}
@@ -124,6 +160,10 @@ func (e *Encoder) encodeSlice(v reflect.Value) error {
 			if err := e.encodeStruct(elem); err != nil {
 				return err
 			}
+		case reflect.Map:
+			if err := e.encodeMap(elem); err != nil {
+				return err
+			}
 		default:
 			return errInvalidType
 		}
@@ -145,73 +185,95 @@ func (e *Encoder) encodePtr(v reflect.Value) error {
 func (e *Encoder) encodeStruct(v reflect.Value) error {
 
 	t := v.Type()
+	if t == recordType {
+		return e.encodeRecord(v.Interface().(Record))
+	}
+
+	if err := e.prepareType(t, func() ([]field, error) {
+		plan, err := e.encodePlanOf(t)
+		if err != nil {
+			return nil, err
+		}
+		e.plan = plan
+		return plan.fields, nil
+	}); err != nil {
+		return err
+	}
+
+	record, err := e.plan.encode(e, v)
+	if err != nil {
+		return err
+	}
+	return e.write(record)
+}
+
+// prepareType is prepare the columns of the type t on the first call, and writes the header.
+// The type cannot be changed after the first call.
+func (e *Encoder) prepareType(t reflect.Type, columns func() ([]field, error)) error {
+
 	if e.typeCache == nil {
+		fields, err := columns()
+		if err != nil {
+			return err
+		}
 		e.typeCache = t
+		e.fieldsCache = fields
 	}
 
 	if e.typeCache != t {
 		return fmt.Errorf("the type cannot be changed during writing")
 	}
 
-	if !e.alreadyWritten && e.UseHeader {
-		if err := e.writeHeader(t); err != nil {
+	if !e.alreadyWritten && e.UseHeader && len(e.fieldsCache) > 0 {
+		if err := e.writeHeader(e.fieldsCache); err != nil {
 			return err
 		}
 	}
 	e.alreadyWritten = true
 
-	return e.writeValue(v, t)
+	return nil
 }
 
-func (e *Encoder) writeHeader(t reflect.Type) error {
-
-	var header []string
-
-	numFiled := t.NumField()
-	for i := 0; i < numFiled; i++ {
-		field := t.Field(i)
+func (e *Encoder) writeHeader(fields []field) error {
 
-		headerName := field.Name
-		if tag, ok := field.Tag.Lookup("csv"); ok {
-			if tag == "-" {
-				continue
-			}
-			headerName = tag
-		}
-
-		header = append(header, headerName)
+	header := make([]string, 0, len(fields))
+	for _, f := range fields {
+		header = append(header, f.name)
 	}
 
-	return e.w.Write(header)
+	return e.write(header)
 }
 
-func (e *Encoder) writeValue(v reflect.Value, t reflect.Type) error {
+func (e *Encoder) encodeValue(rv reflect.Value, f *field) (string, error) {
 
-	var values []string
-
-	numField := t.NumField()
-	for i := 0; i < numField; i++ {
-		field := t.Field(i)
-		if tag, ok := field.Tag.Lookup("csv"); ok {
-			if tag == "-" {
-				continue
+	if encode, ok := e.encodeFuncs.lookup(rv.Type()); ok {
+		return encode(rv.Interface())
+	}
+	if rv.Kind() == reflect.Ptr {
+		if encode, ok := e.encodeFuncs.lookup(rv.Type().Elem()); ok {
+			if rv.IsNil() {
+				return f.format.nilString(e.Nil), nil
 			}
+			return encode(rv.Elem().Interface())
 		}
+	}
 
-		encoded, err := e.encodeValue(v.Field(i))
-		if err != nil {
-			return err
+	if t := rv.Type(); f.format.isTime(t, e.TimeZone) || (t.Kind() == reflect.Ptr && f.format.isTime(t.Elem(), e.TimeZone)) {
+		if rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				return f.format.nilString(e.Nil), nil
+			}
+			rv = rv.Elem()
 		}
-
-		values = append(values, encoded)
+		return f.format.formatTime(rv.Interface().(time.Time), e.TimeZone), nil
 	}
 
-	return e.w.Write(values)
-}
-
-func (e *Encoder) encodeValue(rv reflect.Value) (string, error) {
-
-	v := rv.Interface()
+	// the methods may have a pointer receiver
+	receiver := rv
+	if rv.Kind() != reflect.Ptr && rv.CanAddr() {
+		receiver = rv.Addr()
+	}
+	v := receiver.Interface()
 
 	{
 		var (
@@ -227,7 +289,7 @@ func (e *Encoder) encodeValue(rv reflect.Value) (string, error) {
 
 		if marshalFunc != nil {
 			if reflectutil.IsNilable(rv) && rv.IsNil() {
-				return e.Nil, nil
+				return f.format.nilString(e.Nil), nil
 			} else {
 				encoded, err := marshalFunc()
 				if err != nil {
@@ -240,11 +302,22 @@ func (e *Encoder) encodeValue(rv reflect.Value) (string, error) {
 
 	if rv.Kind() == reflect.Ptr {
 		if rv.IsNil() {
-			return e.Nil, nil
+			return f.format.nilString(e.Nil), nil
 		}
 
 		rv = rv.Elem()
-		v = rv.Interface()
+	}
+	if isNullableType(rv.Type()) {
+		return e.encodeNullable(rv, f)
+	}
+	v = rv.Interface()
+
+	if rv.Kind() == reflect.Bool && (f.format.trueStr != nil || f.format.falseStr != nil) {
+		return f.format.formatBool(rv.Bool()), nil
+	}
+
+	if f.format.format != "" && (reflectutil.IsInt(rv.Kind()) || reflectutil.IsFloat(rv.Kind())) {
+		return fmt.Sprintf(f.format.format, v), nil
 	}
 
 	for _, kind := range wellKnownEncodingKinds {
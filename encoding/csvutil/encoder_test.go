@@ -207,3 +207,198 @@ func TestEncoder_Encode(t *testing.T) {
 		})
 	}
 }
+
+func TestEncoder_Encode_Nested(t *testing.T) {
+
+	type Address struct {
+		City string `csv:"city"`
+		Zip  string `csv:"zip"`
+	}
+	type Base struct {
+		ID int `csv:"id"`
+	}
+	type csvData struct {
+		Base
+		Name    string   `csv:"name"`
+		Address Address  `csv:"address"`
+		Billing *Address `csv:"billing"`
+	}
+
+	d := []csvData{
+		{Base: Base{ID: 1}, Name: "Bob", Address: Address{City: "Tokyo", Zip: "100"}, Billing: &Address{City: "Osaka", Zip: "530"}},
+		{Base: Base{ID: 2}, Name: "Alice", Address: Address{City: "Nagoya", Zip: "450"}},
+	}
+
+	t.Run("DefaultSeparator", func(t *testing.T) {
+		out := new(strings.Builder)
+		enc := csvutil.NewEncoder(out)
+		enc.Nil = "null"
+		if err := enc.Encode(d); err != nil {
+			t.Fatal(err)
+		}
+
+		want := "id,name,address.city,address.zip,billing.city,billing.zip\n" +
+			"1,Bob,Tokyo,100,Osaka,530\n" +
+			"2,Alice,Nagoya,450,null,null\n"
+		if out.String() != want {
+			t.Fatalf("want: %s got: %s", want, out.String())
+		}
+	})
+
+	t.Run("CustomSeparator", func(t *testing.T) {
+		out := new(strings.Builder)
+		enc := csvutil.NewEncoder(out)
+		enc.HeaderSeparator = "_"
+		if err := enc.Encode(d[:1]); err != nil {
+			t.Fatal(err)
+		}
+
+		want := "id,name,address_city,address_zip,billing_city,billing_zip\n" +
+			"1,Bob,Tokyo,100,Osaka,530\n"
+		if out.String() != want {
+			t.Fatalf("want: %s got: %s", want, out.String())
+		}
+	})
+}
+
+func TestEncoder_Encode_FieldFormat(t *testing.T) {
+
+	type csvData struct {
+		Created  time.Time     `csv:"created,layout=2006/01/02 15:04"`
+		Updated  *time.Time    `csv:"updated,layout=2006/01/02 15:04,tz=Asia/Tokyo"`
+		Price    float64       `csv:"price,format=%.2f"`
+		Active   bool          `csv:"active,true=Y,false=N"`
+		Timeout  time.Duration `csv:"timeout"`
+		Standard time.Time     `csv:"standard"`
+	}
+
+	var (
+		created = time.Date(2021, 4, 1, 9, 30, 0, 0, time.UTC)
+		updated = time.Date(2021, 4, 2, 15, 0, 0, 0, time.UTC)
+	)
+	d := []csvData{
+		{Created: created, Updated: &updated, Price: 1.5, Active: true, Timeout: 90 * time.Second, Standard: created},
+		{Created: created, Updated: nil, Price: 2, Active: false, Timeout: time.Millisecond, Standard: created},
+	}
+
+	encoded, err := csvutil.MarshalString(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "created,updated,price,active,timeout,standard\n" +
+		"2021/04/01 09:30,2021/04/03 00:00,1.50,Y,1m30s,2021-04-01T09:30:00Z\n" +
+		"2021/04/01 09:30,,2.00,N,1ms,2021-04-01T09:30:00Z\n"
+	if encoded != want {
+		t.Fatalf("want: %s got: %s", want, encoded)
+	}
+
+	var decoded []csvData
+	if err := csvutil.UnmarshalString(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != len(d) {
+		t.Fatal(decoded)
+	}
+	for i := range d {
+		got, want := decoded[i], d[i]
+		if !got.Created.Equal(want.Created) || !got.Standard.Equal(want.Standard) {
+			t.Fatal(i, got)
+		}
+		if (got.Updated == nil) != (want.Updated == nil) || (got.Updated != nil && !got.Updated.Equal(*want.Updated)) {
+			t.Fatal(i, got)
+		}
+		if got.Updated != nil && got.Updated.Location().String() != "Asia/Tokyo" {
+			t.Fatal(i, got.Updated.Location())
+		}
+		if got.Price != want.Price || got.Active != want.Active || got.Timeout != want.Timeout {
+			t.Fatal(i, got)
+		}
+	}
+
+	var invalid []csvData
+	err = csvutil.UnmarshalString("active\nyes\n", &invalid)
+	if err == nil {
+		t.Fatal(invalid)
+	}
+}
+
+func TestEncoder_Encode_QuotedOption(t *testing.T) {
+
+	type csvData struct {
+		When  time.Time `csv:"when,layout='Jan 2, 2006'"`
+		Price float64   `csv:"price,format='%.1f, ''USD'''"`
+	}
+
+	d := []csvData{{When: time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC), Price: 1.5}}
+
+	encoded, err := csvutil.MarshalString(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "when,price\n\"Jan 2, 2021\",\"1.5, 'USD'\"\n"
+	if encoded != want {
+		t.Fatalf("want: %q got: %q", want, encoded)
+	}
+
+	type timeData struct {
+		When time.Time `csv:"when,layout='Jan 2, 2006'"`
+	}
+	var decoded []timeData
+	if err := csvutil.UnmarshalString(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 1 || !decoded[0].When.Equal(d[0].When) {
+		t.Fatal(decoded)
+	}
+}
+
+func TestEncoder_Encode_InvalidOption(t *testing.T) {
+
+	testSuites := []interface{}{
+		[]struct {
+			When time.Time `csv:"when,layot=2006-01-02"`
+		}{{}},
+		[]struct {
+			When time.Time `csv:"when,layout='Jan 2, 2006"`
+		}{{}},
+		[]struct {
+			When time.Time `csv:"when,layout='Jan 2'x"`
+		}{{}},
+	}
+
+	for i, suite := range testSuites {
+		if _, err := csvutil.MarshalString(suite); err == nil {
+			t.Fatalf("suite:%d must be an error", i)
+		}
+	}
+}
+
+func TestEncoder_Encode_TimeZone(t *testing.T) {
+
+	type csvData struct {
+		Time time.Time `csv:"time,layout=2006-01-02 15:04"`
+	}
+
+	loc := time.FixedZone("JST", 9*60*60)
+
+	out := new(strings.Builder)
+	enc := csvutil.NewEncoder(out)
+	enc.TimeZone = loc
+	if err := enc.Encode(csvData{Time: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "time\n2021-01-01 09:00\n" {
+		t.Fatal(out.String())
+	}
+
+	var decoded []csvData
+	dec := csvutil.NewDecoder(strings.NewReader(out.String()))
+	dec.TimeZone = loc
+	if err := dec.Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded[0].Time.Equal(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatal(decoded[0].Time)
+	}
+}
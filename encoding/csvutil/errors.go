@@ -0,0 +1,82 @@
+package csvutil
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DecodeError is an error that describes the cell which failed to decode.
+type DecodeError struct {
+	Line   int    // Line is the line number of the cell. The first line is 1.
+	Column int    // Column is the column index of the cell, or the start position of the column in fixed-width text. The first column is 0.
+	Header string // Header is the header name of the column. If the header is not used, Header is empty.
+	Field  string // Field is the name of the struct field.
+	Value  string // Value is the raw value of the cell.
+	Err    error  // Err is the underlying error.
+}
+
+func (e *DecodeError) Error() string {
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "csvutil: line %d, column %d", e.Line, e.Column)
+	if e.Header != "" {
+		fmt.Fprintf(b, " (header %q, field %s)", e.Header, e.Field)
+	} else {
+		fmt.Fprintf(b, " (field %s)", e.Field)
+	}
+	fmt.Fprintf(b, ": cannot decode %q: %v", e.Value, e.Err)
+	return b.String()
+}
+
+// Unwrap returns the underlying error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeErrors is a list of DecodeError, returned by Decoder in lenient mode.
+type DecodeErrors []*DecodeError
+
+func (e DecodeErrors) Error() string {
+	switch len(e) {
+	case 0:
+		return "csvutil: no errors"
+	case 1:
+		return e[0].Error()
+	}
+
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "csvutil: %d errors occurred:", len(e))
+	for _, err := range e {
+		b.WriteString("\n\t")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// HeaderError is an error that describes the invalid header.
+type HeaderError struct {
+	Missing   []string // Missing is the required columns which are not in the header.
+	Unknown   []string // Unknown is the columns which are not mapped to any field.
+	Duplicate []string // Duplicate is the columns which appear more than once.
+}
+
+func (e *HeaderError) Error() string {
+	var reasons []string
+	if len(e.Missing) > 0 {
+		reasons = append(reasons, "missing columns "+quoteJoin(e.Missing))
+	}
+	if len(e.Unknown) > 0 {
+		reasons = append(reasons, "unknown columns "+quoteJoin(e.Unknown))
+	}
+	if len(e.Duplicate) > 0 {
+		reasons = append(reasons, "duplicate columns "+quoteJoin(e.Duplicate))
+	}
+	return "csvutil: invalid header: " + strings.Join(reasons, "; ")
+}
+
+func quoteJoin(s []string) string {
+	quoted := make([]string, 0, len(s))
+	for _, v := range s {
+		quoted = append(quoted, fmt.Sprintf("%q", v))
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,277 @@
+package csvutil
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+var (
+	marshalerType       = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	unmarshalerType     = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// field is a CSV column mapped to a (possibly nested) struct field.
+type field struct {
+	name   string       // name is the header name of the column.
+	path   string       // path is the dotted name of the struct field, e.g. Address.City
+	index  []int        // index is the index sequence for reflect.Value.FieldByIndex.
+	column int          // column is the 0-based column index specified by the index option, or -1.
+	typ    reflect.Type // typ is the type of the struct field.
+
+	required bool              // required is whether the column must be present in the header.
+	format   fieldFormat       // format is the format of the value.
+	tag      reflect.StructTag // tag is the struct tag of the field.
+
+	kind    fieldKind // kind is how the value of the field is mapped to the column.
+	element int       // element is the index of the slice element. (fieldKindSliceElement)
+	count   int       // count is the number of the expanded columns. (fieldKindSliceElement)
+	mapKey  string    // mapKey is the key of the map. (fieldKindMapElement)
+	join    string    // join is the separator of the slice elements. (fieldKindJoined)
+}
+
+// typeFields returns the CSV columns of the struct type t.
+// The fields of nested structs are flattened into the columns named `prefix + separator + name`,
+// and the fields of embedded structs are promoted to the parent.
+// The struct type which hasConverter returns true is treated as a single column.
+func typeFields(t reflect.Type, separator string, hasConverter func(reflect.Type) bool) ([]field, error) {
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var fields []field
+	if err := appendTypeFields(&fields, t, "", "", nil, []reflect.Type{t}, separator, hasConverter); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func appendTypeFields(fields *[]field, t reflect.Type, prefix string, pathPrefix string, index []int, visited []reflect.Type, separator string, hasConverter func(reflect.Type) bool) error {
+
+	numField := t.NumField()
+	for i := 0; i < numField; i++ {
+
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			// unexported
+			continue
+		}
+
+		if sf.Tag.Get("csv") == "-" {
+			continue
+		}
+
+		var (
+			fieldIndex = append(append(make([]int, 0, len(index)+1), index...), i)
+			path       = pathPrefix + sf.Name
+		)
+
+		name, options, tagged, err := lookupTag(sf)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", path, err)
+		}
+
+		if nested, ok := nestedStructType(sf.Type, hasConverter); ok && !containsType(visited, nested) {
+			if options.has("index") {
+				return fmt.Errorf("field %s: index option cannot be used with the nested struct", path)
+			}
+			if sf.PkgPath != "" && sf.Type.Kind() == reflect.Ptr {
+				// unexported embedded pointer cannot be allocated
+				continue
+			}
+			nestedPrefix := prefix + name + separator
+			if sf.Anonymous && !tagged {
+				// promote the fields of the embedded struct
+				nestedPrefix = prefix
+			}
+			if err := appendTypeFields(fields, nested, nestedPrefix, path+".", fieldIndex, append(visited, nested), separator, hasConverter); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if sf.PkgPath != "" {
+			// unexported embedded non-struct type
+			continue
+		}
+
+		format, err := parseFieldFormat(options)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", path, err)
+		}
+
+		column := -1
+		if v, ok := options["index"]; ok {
+			column, err = strconv.Atoi(v)
+			if err != nil || column < 0 {
+				return fmt.Errorf("field %s: invalid index option: %q", path, v)
+			}
+		}
+
+		leaf := field{
+			name:     prefix + name,
+			path:     path,
+			index:    fieldIndex,
+			column:   column,
+			typ:      sf.Type,
+			required: options.has("required"),
+			format:   format,
+			tag:      sf.Tag,
+			kind:     fieldKindValue,
+		}
+		if err := appendCollectionFields(fields, leaf, sf.Type, options); err != nil {
+			return fmt.Errorf("field %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// orderFields returns the fields in the order of the columns.
+// The fields which have the index option are placed at the column, and the other fields fill the remaining columns in the order of the declaration.
+// The columns which are not mapped to any field are filled with the padding fields.
+// The fields of fieldKindMapPrefix cannot be placed without the header, so they are appended at the end.
+func orderFields(fields []field) ([]field, error) {
+
+	var (
+		columns  []*field
+		prefixes []field
+	)
+	for i := range fields {
+		f := &fields[i]
+		if f.kind == fieldKindMapPrefix {
+			if f.column >= 0 {
+				return nil, fmt.Errorf("field %s: index option requires keys option to expand the map", f.path)
+			}
+			prefixes = append(prefixes, *f)
+			continue
+		}
+
+		if f.column < 0 {
+			continue
+		}
+		for len(columns) <= f.column {
+			columns = append(columns, nil)
+		}
+		if dup := columns[f.column]; dup != nil {
+			return nil, fmt.Errorf("field %s: index %d is already used by field %s", f.path, f.column, dup.path)
+		}
+		columns[f.column] = f
+	}
+
+	next := 0
+	for i := range fields {
+		f := &fields[i]
+		if f.kind == fieldKindMapPrefix || f.column >= 0 {
+			continue
+		}
+		for next < len(columns) && columns[next] != nil {
+			next++
+		}
+		if next == len(columns) {
+			columns = append(columns, nil)
+		}
+		columns[next] = f
+	}
+
+	ordered := make([]field, 0, len(columns)+len(prefixes))
+	for i, f := range columns {
+		if f == nil {
+			ordered = append(ordered, field{column: i, kind: fieldKindPadding})
+			continue
+		}
+		ordered = append(ordered, *f)
+	}
+	return append(ordered, prefixes...), nil
+}
+
+// selectFields returns the fields in the order of the columns specified by the header names.
+func selectFields(fields []field, columns []string) ([]field, error) {
+
+	selected := make([]field, 0, len(columns))
+	for _, name := range columns {
+		found := false
+		for _, f := range fields {
+			if f.name == name && f.kind != fieldKindMapPrefix {
+				selected = append(selected, f)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column: %q", name)
+		}
+	}
+	return selected, nil
+}
+
+// nestedStructType returns the struct type if t is a struct (or pointer of struct) that should be flattened.
+// The struct which can encode/decode itself or has a converter is treated as a single column.
+func nestedStructType(t reflect.Type, hasConverter func(reflect.Type) bool) (reflect.Type, bool) {
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+	if hasConverter(t) || isNullableType(t) {
+		return nil, false
+	}
+
+	for _, typ := range []reflect.Type{t, reflect.PtrTo(t)} {
+		if typ.Implements(marshalerType) || typ.Implements(unmarshalerType) ||
+			typ.Implements(textMarshalerType) || typ.Implements(textUnmarshalerType) {
+			return nil, false
+		}
+	}
+
+	return t, true
+}
+
+func containsType(types []reflect.Type, t reflect.Type) bool {
+	for _, typ := range types {
+		if typ == t {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldByIndex returns the nested field of v.
+// If a nil pointer is found on the way, fieldByIndex returns false.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+
+	return v, true
+}
+
+// fieldByIndexAlloc returns the nested field of v.
+// If a nil pointer is found on the way, fieldByIndexAlloc allocates it.
+func fieldByIndexAlloc(v reflect.Value, index []int) reflect.Value {
+
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+
+	return v
+}
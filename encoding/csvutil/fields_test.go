@@ -0,0 +1,93 @@
+package csvutil_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.nanasi880.dev/x/encoding/csvutil"
+)
+
+func TestDecoder_Decode_Index(t *testing.T) {
+
+	type csvData struct {
+		Name   string `csv:"name,index=2"`
+		ID     int    `csv:"id,index=0"`
+		Scores []int  `csv:"score,index=4,expand=2"`
+		Note   string `csv:"note"`
+	}
+
+	const input = "1,memo,foo,ignored,10,20\n" +
+		"2,,bar,ignored,30,40\n"
+
+	dec := csvutil.NewDecoder(strings.NewReader(input))
+	dec.UseHeader = false
+
+	var decoded []csvData
+	if err := dec.Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []csvData{
+		{Name: "foo", ID: 1, Scores: []int{10, 20}, Note: "memo"},
+		{Name: "bar", ID: 2, Scores: []int{30, 40}, Note: ""},
+	}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Fatal(decoded)
+	}
+
+	out := new(strings.Builder)
+	enc := csvutil.NewEncoder(out)
+	if err := enc.Encode(want); err != nil {
+		t.Fatal(err)
+	}
+
+	// the column which is not mapped to any field is encoded as the empty cell
+	const wantEncoded = "id,note,name,,score_1,score_2\n" +
+		"1,memo,foo,,10,20\n" +
+		"2,,bar,,30,40\n"
+	if out.String() != wantEncoded {
+		t.Fatalf("want: %q got: %q", wantEncoded, out.String())
+	}
+}
+
+func TestDecoder_Decode_Index_Duplicate(t *testing.T) {
+
+	type csvData struct {
+		A string `csv:"a,index=1"`
+		B string `csv:"b,index=1"`
+	}
+
+	dec := csvutil.NewDecoder(strings.NewReader("x,y\n"))
+	dec.UseHeader = false
+
+	var decoded []csvData
+	if err := dec.Decode(&decoded); err == nil {
+		t.Fatal("the duplicate index must be an error")
+	}
+}
+
+func TestEncoder_Encode_Columns(t *testing.T) {
+
+	type csvData struct {
+		ID      int    `csv:"id"`
+		Name    string `csv:"name"`
+		Comment string `csv:"comment"`
+	}
+
+	out := new(strings.Builder)
+	enc := csvutil.NewEncoder(out)
+	enc.Columns = []string{"name", "id"}
+	if err := enc.Encode([]csvData{{ID: 1, Name: "foo", Comment: "bar"}}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "name,id\nfoo,1\n" {
+		t.Fatal(out.String())
+	}
+
+	enc = csvutil.NewEncoder(new(strings.Builder))
+	enc.Columns = []string{"unknown"}
+	if err := enc.Encode([]csvData{{}}); err == nil {
+		t.Fatal("the unknown column must be an error")
+	}
+}
@@ -0,0 +1,228 @@
+package csvutil
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// fixedWidthField is a column of the fixed-width text mapped to a struct field.
+// The column is specified by `fixed` struct tag, e.g. `fixed:"1-10,align=right,pad=0,truncate"`.
+//
+//	1-10          the position of the column. The first position is 1, and the end position is inclusive.
+//	align=right   the alignment of the value, left (default) or right.
+//	pad=0         the padding character (default: space).
+//	truncate      the value longer than the column is truncated. If not specified, it is an error.
+type fixedWidthField struct {
+	field
+	start      int // start is the start position of the column. (0-based, inclusive)
+	end        int // end is the end position of the column. (0-based, exclusive)
+	alignRight bool
+	pad        rune
+	truncate   bool
+}
+
+func (f *fixedWidthField) width() int {
+	return f.end - f.start
+}
+
+// fixedWidthFields returns the columns of the struct type t which have `fixed` struct tag.
+func fixedWidthFields(t reflect.Type, useRunes bool, hasConverter func(reflect.Type) bool) ([]fixedWidthField, error) {
+
+	fields, err := typeFields(t, ".", hasConverter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]fixedWidthField, 0, len(fields))
+	for _, f := range fields {
+		tag, ok := f.tag.Lookup("fixed")
+		if !ok || tag == "-" {
+			continue
+		}
+		if f.kind != fieldKindValue && f.kind != fieldKindJoined {
+			return nil, fmt.Errorf("field %s: expand option is not supported in fixed-width text", f.path)
+		}
+
+		fixed, err := parseFixedWidthTag(f, tag, useRunes)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.path, err)
+		}
+		result = append(result, fixed)
+	}
+
+	return result, nil
+}
+
+// fixedTagOptions is the keys of the options of the fixed struct tag.
+var fixedTagOptions = []string{"align", "pad", "truncate"}
+
+func parseFixedWidthTag(f field, tag string, useRunes bool) (fixedWidthField, error) {
+
+	position, options, err := parseTag(tag)
+	if err != nil {
+		return fixedWidthField{}, fmt.Errorf("invalid fixed tag: %q: %w", tag, err)
+	}
+	if err := options.validate(fixedTagOptions); err != nil {
+		return fixedWidthField{}, fmt.Errorf("invalid fixed tag: %q: %w", tag, err)
+	}
+
+	i := strings.IndexByte(position, '-')
+	if i < 0 {
+		return fixedWidthField{}, fmt.Errorf("invalid fixed tag: %q", tag)
+	}
+	start, err := strconv.Atoi(position[:i])
+	if err != nil {
+		return fixedWidthField{}, fmt.Errorf("invalid fixed tag: %q: %w", tag, err)
+	}
+	end, err := strconv.Atoi(position[i+1:])
+	if err != nil {
+		return fixedWidthField{}, fmt.Errorf("invalid fixed tag: %q: %w", tag, err)
+	}
+	if start < 1 || end < start {
+		return fixedWidthField{}, fmt.Errorf("invalid fixed tag: %q: invalid position", tag)
+	}
+
+	result := fixedWidthField{
+		field:      f,
+		start:      start - 1,
+		end:        end,
+		alignRight: false,
+		pad:        ' ',
+		truncate:   options.has("truncate"),
+	}
+
+	switch align := options["align"]; align {
+	case "", "left":
+	case "right":
+		result.alignRight = true
+	default:
+		return fixedWidthField{}, fmt.Errorf("invalid fixed tag: %q: unknown align %q", tag, align)
+	}
+
+	if pad, ok := options["pad"]; ok {
+		r, size := utf8.DecodeRuneInString(pad)
+		if size == 0 || size != len(pad) {
+			return fixedWidthField{}, fmt.Errorf("invalid fixed tag: %q: pad must be a single character", tag)
+		}
+		if !useRunes && r >= utf8.RuneSelf {
+			return fixedWidthField{}, fmt.Errorf("invalid fixed tag: %q: pad must be a single byte character", tag)
+		}
+		result.pad = r
+	}
+
+	return result, nil
+}
+
+// fixedWidthColumns is a line of the fixed-width text, indexed by byte or rune.
+type fixedWidthColumns struct {
+	useRunes bool
+	bytes    []byte
+	runes    []rune
+}
+
+func (c *fixedWidthColumns) length(s string) int {
+	if c.useRunes {
+		return utf8.RuneCountInString(s)
+	}
+	return len(s)
+}
+
+// substring returns s[start:end] in the unit of columns. The out of range is ignored.
+func (c *fixedWidthColumns) substring(s string, start int, end int) string {
+	if c.useRunes {
+		if c.runes == nil {
+			c.runes = []rune(s)
+		}
+		return string(c.runes[clampIndex(start, len(c.runes)):clampIndex(end, len(c.runes))])
+	}
+	return s[clampIndex(start, len(s)):clampIndex(end, len(s))]
+}
+
+func clampIndex(i int, length int) int {
+	if i > length {
+		return length
+	}
+	return i
+}
+
+// reset prepares a blank line of the width.
+func (c *fixedWidthColumns) reset(width int) {
+	if c.useRunes {
+		c.runes = c.runes[:0]
+		for i := 0; i < width; i++ {
+			c.runes = append(c.runes, ' ')
+		}
+		return
+	}
+	c.bytes = c.bytes[:0]
+	for i := 0; i < width; i++ {
+		c.bytes = append(c.bytes, ' ')
+	}
+}
+
+// put writes the value into the column of the line.
+func (c *fixedWidthColumns) put(f *fixedWidthField, value string) error {
+
+	width := f.width()
+	length := c.length(value)
+	if length > width {
+		if !f.truncate {
+			return fmt.Errorf("field %s: the value %q exceeds the width %d", f.path, value, width)
+		}
+		value = c.cut(value, width)
+		length = width
+	}
+
+	var (
+		sign    string
+		padding = strings.Repeat(string(f.pad), width-length)
+	)
+	if f.alignRight && f.pad == '0' && len(value) > 0 && (value[0] == '-' || value[0] == '+') {
+		// zero padding must be after the sign
+		sign, value = value[:1], value[1:]
+	}
+	if f.alignRight {
+		value = sign + padding + value
+	} else {
+		value = value + padding
+	}
+
+	if c.useRunes {
+		copy(c.runes[f.start:f.end], []rune(value))
+	} else {
+		copy(c.bytes[f.start:f.end], value)
+	}
+	return nil
+}
+
+func (c *fixedWidthColumns) cut(s string, width int) string {
+	if c.useRunes {
+		return string([]rune(s)[:width])
+	}
+	return s[:width]
+}
+
+func (c *fixedWidthColumns) String() string {
+	if c.useRunes {
+		return string(c.runes)
+	}
+	return string(c.bytes)
+}
+
+// trimPadding removes the padding of the value read from the column.
+func (f *fixedWidthField) trimPadding(s string) string {
+	pad := string(f.pad)
+	if !f.alignRight {
+		return strings.TrimRight(s, pad)
+	}
+
+	trimmed := strings.TrimLeft(s, pad)
+	if trimmed == "" && s != "" && f.pad == '0' {
+		// all zero
+		return "0"
+	}
+	return trimmed
+}
@@ -0,0 +1,204 @@
+package csvutil
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"go.nanasi880.dev/x/unsafe/unsafeutil"
+)
+
+var (
+	errInvalidFixedWidthDecodeType     = fmt.Errorf("pointer of slice of struct (or struct pointer) only")
+	errInvalidFixedWidthDecodeNextType = fmt.Errorf("pointer of struct only")
+)
+
+// UnmarshalFixedWidthString is decodes a slice of a structure from fixed-width text string.
+func UnmarshalFixedWidthString(text string, out interface{}) error {
+	return UnmarshalFixedWidth(unsafeutil.StringToBytes(text), out)
+}
+
+// UnmarshalFixedWidth is decodes a slice of a structure from fixed-width text.
+func UnmarshalFixedWidth(text []byte, out interface{}) error {
+	return NewFixedWidthDecoder(bytes.NewReader(text)).Decode(out)
+}
+
+// NewFixedWidthDecoder is create fixed-width text decoder.
+func NewFixedWidthDecoder(r io.Reader) *FixedWidthDecoder {
+	return &FixedWidthDecoder{
+		UseRunes:    false,
+		Nil:         "",
+		EmptyCell:   EmptyCellDecode,
+		TimeZone:    nil,
+		r:           bufio.NewReader(r),
+		line:        0,
+		typeCache:   nil,
+		fieldsCache: nil,
+		decodeFuncs: nil,
+	}
+}
+
+// FixedWidthDecoder reads fixed-width text values from an input stream.
+// The columns are specified by `fixed` struct tag in the same way as FixedWidthEncoder,
+// and the values are decoded in the same way as Decoder, including Unmarshaler, encoding.TextUnmarshaler and the options of `csv` struct tag.
+// The padding is removed before decoding, and the empty column is decoded as nil if the field is a pointer and Nil is empty.
+type FixedWidthDecoder struct {
+	UseRunes    bool           // If true, the positions of the columns are counted in runes instead of bytes.
+	Nil         string         // Nil is the value of nil pointer.
+	EmptyCell   EmptyCell      // EmptyCell is the policy of the empty column. The `empty` tag option takes precedence.
+	TimeZone    *time.Location // TimeZone is the default time zone of time.Time. The `tz` tag option takes precedence.
+	r           *bufio.Reader
+	line        int
+	typeCache   reflect.Type
+	fieldsCache []fixedWidthField
+	decodeFuncs decodeFuncs
+}
+
+// Register is register the DecodeFunc of the type t to the FixedWidthDecoder in the same way as Decoder.Register.
+func (d *FixedWidthDecoder) Register(t reflect.Type, f DecodeFunc) {
+	d.decodeFuncs.register(t, f)
+}
+
+// Decode is decodes a slice of a structure from fixed-width text, read from the io.Reader specified by NewFixedWidthDecoder.
+// Empty lines are skipped.
+func (d *FixedWidthDecoder) Decode(out interface{}) error {
+
+	if out == nil {
+		return fmt.Errorf("nil")
+	}
+
+	elemType, err := d.getValueType(out)
+	if err != nil {
+		return err
+	}
+
+	var (
+		slice  = reflect.ValueOf(out).Elem()
+		result = reflect.MakeSlice(slice.Type(), 0, 0)
+	)
+	for {
+		// the struct is decoded through the pointer if the element is a pointer
+		elem := reflect.New(elemType).Elem()
+		access := elem
+		if elemType.Kind() == reflect.Ptr {
+			elem = reflect.New(elemType.Elem())
+			access = elem.Elem()
+		}
+
+		err := d.decodeNext(access)
+		if err == io.EOF {
+			slice.Set(result)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		result = reflect.Append(result, elem)
+	}
+}
+
+// DecodeNext is decodes the next line into a structure.
+// The out must be a pointer of struct, and it is reset to zero value before decoding.
+// If there are no more lines, DecodeNext returns io.EOF.
+func (d *FixedWidthDecoder) DecodeNext(out interface{}) error {
+
+	if out == nil {
+		return fmt.Errorf("nil")
+	}
+
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct || rv.Elem().Type() == recordType {
+		return errInvalidFixedWidthDecodeNextType
+	}
+
+	return d.decodeNext(rv.Elem())
+}
+
+// getValueType returns the element type of the slice pointed by v.
+// Unlike Decoder, the element must be a struct or a pointer of struct, because the columns are defined by the struct tags.
+func (d *FixedWidthDecoder) getValueType(v interface{}) (reflect.Type, error) {
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return nil, errInvalidFixedWidthDecodeType
+	}
+
+	elemType := rv.Elem().Type().Elem()
+	switch {
+	case elemType == recordType:
+		return nil, errInvalidFixedWidthDecodeType
+	case elemType.Kind() == reflect.Struct:
+		return elemType, nil
+	case elemType.Kind() == reflect.Ptr && elemType.Elem().Kind() == reflect.Struct && elemType.Elem() != recordType:
+		return elemType, nil
+	default:
+		return nil, errInvalidFixedWidthDecodeType
+	}
+}
+
+func (d *FixedWidthDecoder) decodeNext(access reflect.Value) error {
+
+	t := access.Type()
+	if d.typeCache != t {
+		fields, err := fixedWidthFields(t, d.UseRunes, d.decodeFuncs.has)
+		if err != nil {
+			return err
+		}
+		d.typeCache = t
+		d.fieldsCache = fields
+	}
+
+	text, err := d.readLine()
+	if err != nil {
+		return err
+	}
+
+	// values are decoded in the same way as Decoder
+	values := Decoder{
+		Nil:         d.Nil,
+		EmptyCell:   d.EmptyCell,
+		TimeZone:    d.TimeZone,
+		decodeFuncs: d.decodeFuncs,
+	}
+
+	access.Set(reflect.Zero(t))
+	line := fixedWidthColumns{useRunes: d.UseRunes}
+	for i := range d.fieldsCache {
+		f := &d.fieldsCache[i]
+
+		raw := f.trimPadding(line.substring(text, f.start, f.end))
+		fv := fieldByIndexAlloc(access, f.index)
+		if err := values.decodeField(raw, fv, &f.field); err != nil {
+			return &DecodeError{
+				Line:   d.line,
+				Column: f.start,
+				Field:  f.path,
+				Value:  raw,
+				Err:    err,
+			}
+		}
+	}
+
+	return nil
+}
+
+// readLine returns the next non-empty line without the line ending.
+func (d *FixedWidthDecoder) readLine() (string, error) {
+	for {
+		text, err := d.r.ReadString('\n')
+		if err != nil && (err != io.EOF || text == "") {
+			return "", err
+		}
+		d.line++
+
+		text = strings.TrimSuffix(text, "\n")
+		text = strings.TrimSuffix(text, "\r")
+		if text != "" {
+			return text, nil
+		}
+	}
+}
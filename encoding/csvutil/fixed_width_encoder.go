@@ -0,0 +1,160 @@
+package csvutil
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// MarshalFixedWidthString is encodes a structure or slice of a structure into fixed-width text string.
+func MarshalFixedWidthString(v interface{}) (string, error) {
+	buf := new(strings.Builder)
+	err := NewFixedWidthEncoder(buf).Encode(v)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// MarshalFixedWidth is encodes a structure or slice of a structure into fixed-width text.
+func MarshalFixedWidth(v interface{}) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	err := NewFixedWidthEncoder(buf).Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// NewFixedWidthEncoder is create fixed-width text encoder.
+func NewFixedWidthEncoder(w io.Writer) *FixedWidthEncoder {
+	return &FixedWidthEncoder{
+		UseCRLF:     false,
+		UseRunes:    false,
+		Nil:         "",
+		TimeZone:    nil,
+		w:           bufio.NewWriter(w),
+		typeCache:   nil,
+		fieldsCache: nil,
+		encodeFuncs: nil,
+	}
+}
+
+// A FixedWidthEncoder writes fixed-width text values to an output stream.
+// The columns are specified by `fixed` struct tag, e.g. `fixed:"1-10,align=right,pad=0,truncate"`,
+// and the values are encoded in the same way as Encoder, including Marshaler, encoding.TextMarshaler and the options of `csv` struct tag.
+// The fields without `fixed` struct tag are ignored.
+type FixedWidthEncoder struct {
+	UseCRLF     bool
+	UseRunes    bool           // If true, the positions of the columns are counted in runes instead of bytes.
+	Nil         string         // Nil is the value of nil pointer.
+	TimeZone    *time.Location // TimeZone is the default time zone of time.Time. The `tz` tag option takes precedence.
+	w           *bufio.Writer
+	typeCache   reflect.Type
+	fieldsCache []fixedWidthField
+	width       int
+	line        fixedWidthColumns
+	encodeFuncs encodeFuncs
+}
+
+// Register is register the EncodeFunc of the type t to the FixedWidthEncoder in the same way as Encoder.Register.
+func (e *FixedWidthEncoder) Register(t reflect.Type, f EncodeFunc) {
+	e.encodeFuncs.register(t, f)
+}
+
+// Encode is encodes a structure or slice of a structure into fixed-width text and output to the io.Writer specified by NewFixedWidthEncoder.
+func (e *FixedWidthEncoder) Encode(v interface{}) (err error) {
+
+	if v == nil {
+		return fmt.Errorf("`v` is nil")
+	}
+
+	defer func() {
+		if err == nil {
+			err = e.w.Flush()
+		}
+	}()
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		l := rv.Len()
+		for i := 0; i < l; i++ {
+			if err := e.encodeElem(rv.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return e.encodeElem(rv)
+	}
+}
+
+func (e *FixedWidthEncoder) encodeElem(v reflect.Value) error {
+
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return errInvalidType
+	}
+
+	t := v.Type()
+	if e.typeCache == nil {
+		fields, err := fixedWidthFields(t, e.UseRunes, e.encodeFuncs.has)
+		if err != nil {
+			return err
+		}
+		e.typeCache = t
+		e.fieldsCache = fields
+		e.width = 0
+		for _, f := range fields {
+			if f.end > e.width {
+				e.width = f.end
+			}
+		}
+	}
+
+	if e.typeCache != t {
+		return fmt.Errorf("the type cannot be changed during writing")
+	}
+
+	// values are encoded in the same way as Encoder
+	values := Encoder{
+		Nil:         e.Nil,
+		TimeZone:    e.TimeZone,
+		encodeFuncs: e.encodeFuncs,
+	}
+
+	e.line.useRunes = e.UseRunes
+	e.line.reset(e.width)
+	for i := range e.fieldsCache {
+		f := &e.fieldsCache[i]
+
+		encoded := f.format.nilString(e.Nil)
+		if fv, ok := fieldByIndex(v, f.index); ok {
+			var err error
+			encoded, err = values.encodeField(fv, &f.field)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := e.line.put(f, encoded); err != nil {
+			return err
+		}
+	}
+
+	if _, err := e.w.WriteString(e.line.String()); err != nil {
+		return err
+	}
+	if e.UseCRLF {
+		_, err := e.w.WriteString("\r\n")
+		return err
+	}
+	return e.w.WriteByte('\n')
+}
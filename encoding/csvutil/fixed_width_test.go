@@ -0,0 +1,172 @@
+package csvutil_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.nanasi880.dev/x/encoding/csvutil"
+)
+
+type fixedWidthCode string
+
+func (c fixedWidthCode) MarshalCSV() ([]byte, error) {
+	return []byte("C" + string(c)), nil
+}
+
+func (c *fixedWidthCode) UnmarshalCSV(b []byte) error {
+	if len(b) == 0 || b[0] != 'C' {
+		return fmt.Errorf("invalid code: %s", b)
+	}
+	*c = fixedWidthCode(b[1:])
+	return nil
+}
+
+func ExampleMarshalFixedWidthString() {
+
+	type record struct {
+		ID     int       `fixed:"1-5,align=right,pad=0"`
+		Name   string    `fixed:"6-15"`
+		Amount float64   `fixed:"16-25,align=right" csv:",format=%.2f"`
+		Date   time.Time `fixed:"26-33" csv:",layout=20060102"`
+		Memo   string
+	}
+
+	d := []record{
+		{ID: 1, Name: "Bob", Amount: 1200, Date: time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 42, Name: "Alice", Amount: -3.5, Date: time.Date(2021, 4, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	encoded, err := csvutil.MarshalFixedWidthString(d)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Print(encoded)
+	// Output:
+	// 00001Bob          1200.0020210401
+	// 00042Alice          -3.5020210402
+}
+
+func TestFixedWidth_RoundTrip(t *testing.T) {
+
+	type record struct {
+		ID     int            `fixed:"1-5,align=right,pad=0"`
+		Name   string         `fixed:"6-10,truncate"`
+		Code   fixedWidthCode `fixed:"11-14"`
+		Delta  int            `fixed:"15-18,align=right,pad=0"`
+		Ratio  *float64       `fixed:"19-23,align=right"`
+		Ignore string
+	}
+
+	ratio := 0.25
+	d := []record{
+		{ID: 1, Name: "Bob", Code: "AB", Delta: -5, Ratio: &ratio},
+		{ID: 0, Name: "Christopher", Code: "X", Delta: 0, Ratio: nil},
+	}
+
+	encoded, err := csvutil.MarshalFixedWidthString(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "00001Bob  CAB -005 0.25\n" +
+		"00000ChrisCX  0000     \n"
+	if encoded != want {
+		t.Fatalf("want: %q got: %q", want, encoded)
+	}
+
+	var decoded []record
+	if err := csvutil.UnmarshalFixedWidthString(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	d[1].Name = "Chris"
+	if !reflect.DeepEqual(decoded, d) {
+		t.Fatal(decoded)
+	}
+
+	var pointers []*record
+	if err := csvutil.UnmarshalFixedWidthString(encoded, &pointers); err != nil {
+		t.Fatal(err)
+	}
+	if len(pointers) != len(d) || !reflect.DeepEqual(*pointers[0], d[0]) || !reflect.DeepEqual(*pointers[1], d[1]) {
+		t.Fatal(pointers)
+	}
+}
+
+func TestFixedWidth_Runes(t *testing.T) {
+
+	type record struct {
+		Name string `fixed:"1-4"`
+		City string `fixed:"5-7,align=right,pad=＊"`
+	}
+
+	d := []record{{Name: "山田", City: "東京"}}
+
+	buf := new(bytes.Buffer)
+	encoder := csvutil.NewFixedWidthEncoder(buf)
+	encoder.UseRunes = true
+	encoder.UseCRLF = true
+	if err := encoder.Encode(d); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "山田  ＊東京\r\n" {
+		t.Fatalf("%q", buf.String())
+	}
+
+	decoder := csvutil.NewFixedWidthDecoder(buf)
+	decoder.UseRunes = true
+	var decoded record
+	if err := decoder.DecodeNext(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded != d[0] {
+		t.Fatal(decoded)
+	}
+}
+
+func TestFixedWidth_Error(t *testing.T) {
+
+	type record struct {
+		Name string `fixed:"1-3"`
+		Age  int    `fixed:"4-6,align=right"`
+	}
+
+	if _, err := csvutil.MarshalFixedWidthString(record{Name: "Alice"}); err == nil {
+		t.Fatal("too long value must be rejected")
+	}
+
+	var decoded []record
+	err := csvutil.UnmarshalFixedWidthString("Bob 18\nAlix18\n", &decoded)
+
+	var decodeErr *csvutil.DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatal(err)
+	}
+	if decodeErr.Line != 2 || decodeErr.Column != 3 || decodeErr.Field != "Age" || decodeErr.Value != "x18" {
+		t.Fatal(decodeErr)
+	}
+}
+
+func TestFixedWidth_InvalidType(t *testing.T) {
+
+	for _, out := range []interface{}{
+		&[]map[string]string{},
+		&[]map[string]interface{}{},
+		&[]csvutil.Record{},
+		&[]*csvutil.Record{},
+		&[]int{},
+	} {
+		if err := csvutil.UnmarshalFixedWidthString("abc\n", out); err == nil {
+			t.Fatalf("%T must be rejected", out)
+		}
+	}
+
+	dec := csvutil.NewFixedWidthDecoder(strings.NewReader("abc\n"))
+	if err := dec.DecodeNext(&csvutil.Record{}); err == nil {
+		t.Fatal("Record must be rejected")
+	}
+}
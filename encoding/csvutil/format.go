@@ -0,0 +1,146 @@
+package csvutil
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// fieldFormat is the per-field format specified by the options of the csv struct tag.
+//
+//	layout=2006/01/02  the layout of time.Time (default: time.RFC3339Nano)
+//	tz=Asia/Tokyo      the time zone of time.Time
+//	format=%.2f        the fmt format of the number when encoding
+//	true=Y,false=N     the representation of bool
+//	default=0          the value used when decoding the empty cell
+//	nil=NULL           the representation of nil, instead of Nil of Encoder and Decoder
+//	empty=zero         the policy of the empty cell when decoding (decode, zero or error), instead of EmptyCell of Decoder
+//
+// The value which contains commas is quoted by single quotes, e.g. layout='Jan 2, 2006'.
+type fieldFormat struct {
+	layout       string
+	location     *time.Location
+	format       string
+	trueStr      *string
+	falseStr     *string
+	defaultValue *string
+	nilStr       *string
+	emptyCell    *EmptyCell
+}
+
+func parseFieldFormat(options tagOptions) (fieldFormat, error) {
+
+	var f fieldFormat
+
+	f.layout = options["layout"]
+	f.format = options["format"]
+
+	if tz, ok := options["tz"]; ok {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return f, fmt.Errorf("invalid tz option: %w", err)
+		}
+		f.location = loc
+	}
+
+	if v, ok := options["true"]; ok {
+		f.trueStr = &v
+	}
+	if v, ok := options["false"]; ok {
+		f.falseStr = &v
+	}
+	if v, ok := options["default"]; ok {
+		f.defaultValue = &v
+	}
+	if v, ok := options["nil"]; ok {
+		f.nilStr = &v
+	}
+	if v, ok := options["empty"]; ok {
+		emptyCell, err := parseEmptyCell(v)
+		if err != nil {
+			return f, err
+		}
+		f.emptyCell = &emptyCell
+	}
+
+	return f, nil
+}
+
+// isTime returns whether the time.Time should be formatted by fieldFormat instead of encoding.TextMarshaler.
+func (f *fieldFormat) isTime(t reflect.Type, defaultLocation *time.Location) bool {
+	return t == timeType && (f.layout != "" || f.location != nil || defaultLocation != nil)
+}
+
+func (f *fieldFormat) timeLayout() string {
+	if f.layout == "" {
+		return time.RFC3339Nano
+	}
+	return f.layout
+}
+
+func (f *fieldFormat) timeLocation(defaultLocation *time.Location) *time.Location {
+	if f.location != nil {
+		return f.location
+	}
+	return defaultLocation
+}
+
+func (f *fieldFormat) formatTime(t time.Time, defaultLocation *time.Location) string {
+	if loc := f.timeLocation(defaultLocation); loc != nil {
+		t = t.In(loc)
+	}
+	return t.Format(f.timeLayout())
+}
+
+func (f *fieldFormat) parseTime(raw string, defaultLocation *time.Location) (time.Time, error) {
+	loc := f.timeLocation(defaultLocation)
+	if loc == nil {
+		return time.Parse(f.timeLayout(), raw)
+	}
+
+	t, err := time.ParseInLocation(f.timeLayout(), raw, loc)
+	if err != nil {
+		return t, err
+	}
+	return t.In(loc), nil
+}
+
+func (f *fieldFormat) formatBool(v bool) string {
+	if v {
+		if f.trueStr != nil {
+			return *f.trueStr
+		}
+		return "true"
+	}
+	if f.falseStr != nil {
+		return *f.falseStr
+	}
+	return "false"
+}
+
+func (f *fieldFormat) parseBool(raw string) (bool, error) {
+	if f.trueStr != nil && raw == *f.trueStr {
+		return true, nil
+	}
+	if f.falseStr != nil && raw == *f.falseStr {
+		return false, nil
+	}
+	if f.trueStr != nil && f.falseStr != nil {
+		return false, fmt.Errorf("invalid bool: %q", raw)
+	}
+	return strconv.ParseBool(raw)
+}
+
+func parseDuration(raw string) (time.Duration, error) {
+	// integer is treated as nanoseconds
+	if v, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Duration(v), nil
+	}
+	return time.ParseDuration(raw)
+}
@@ -0,0 +1,117 @@
+package csvutil
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// FormulaProtection is how Encoder treats the cells which may be interpreted as a formula by spreadsheet applications.
+// The cells beginning with '=', '+', '-', '@', tab or carriage return are the target, except for the numbers such as "-1".
+type FormulaProtection int
+
+const (
+	// FormulaProtectionNone is write the cells as is.
+	FormulaProtectionNone FormulaProtection = iota
+
+	// FormulaProtectionEscape is escape the cells by prepending a single quote.
+	// The cells which begin with single quotes followed by a formula are escaped too, so that the escape can be removed exactly by Decoder.UnescapeFormula.
+	FormulaProtectionEscape
+
+	// FormulaProtectionReject is return an error if the cell is the target.
+	FormulaProtectionReject
+)
+
+// formulaEscape is the prefix which prevents the cell from being interpreted as a formula.
+const formulaEscape = '\''
+
+// isFormula returns whether the cell may be interpreted as a formula.
+func isFormula(s string) bool {
+	if s == "" {
+		return false
+	}
+	switch s[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+	default:
+		return false
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		// the number is safe
+		return false
+	}
+	return true
+}
+
+// needsEscape returns whether the cell is escaped by FormulaProtectionEscape.
+// The cell is a formula after removing the leading single quotes, so that the existing single quote is not mistaken for the escape.
+func needsEscape(s string) bool {
+	for len(s) > 0 && s[0] == formulaEscape {
+		s = s[1:]
+	}
+	return isFormula(s)
+}
+
+// protectFormula returns the record protected by the FormulaProtection.
+// The record is not modified, a new slice is returned if any cell is escaped.
+func protectFormula(record []string, protection FormulaProtection) ([]string, error) {
+
+	if protection == FormulaProtectionNone {
+		return record, nil
+	}
+
+	copied := false
+	for i, cell := range record {
+		if protection == FormulaProtectionReject {
+			if isFormula(cell) {
+				return nil, fmt.Errorf("the cell %q may be interpreted as a formula", cell)
+			}
+			continue
+		}
+		if !needsEscape(cell) {
+			continue
+		}
+
+		if !copied {
+			record = append(make([]string, 0, len(record)), record...)
+			copied = true
+		}
+		record[i] = string(formulaEscape) + cell
+	}
+
+	return record, nil
+}
+
+// unescapeFormula removes the escape added by FormulaProtectionEscape from the record in place.
+func unescapeFormula(record []string) {
+	for i, cell := range record {
+		if len(cell) > 1 && cell[0] == formulaEscape && needsEscape(cell[1:]) {
+			record[i] = cell[1:]
+		}
+	}
+}
+
+// write writes the record to the csv.Writer with the FormulaProtection, BOM and QuoteAll options.
+func (e *Encoder) write(record []string) error {
+	record, err := protectFormula(record, e.FormulaProtection)
+	if err != nil {
+		return err
+	}
+	if err := e.writeBOM(); err != nil {
+		return err
+	}
+	if e.QuoteAll {
+		return e.writeQuoted(record)
+	}
+	return e.w.Write(record)
+}
+
+// read reads the record from the csv.Reader and removes the formula escape if necessary.
+func (d *Decoder) read() ([]string, error) {
+	record, err := d.r.Read()
+	if err != nil {
+		return record, d.adjustParseError(err)
+	}
+	if d.UnescapeFormula {
+		unescapeFormula(record)
+	}
+	return record, nil
+}
@@ -0,0 +1,117 @@
+package csvutil_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.nanasi880.dev/x/encoding/csvutil"
+)
+
+func TestEncoder_FormulaProtection(t *testing.T) {
+
+	type csvData struct {
+		Comment string  `csv:"comment"`
+		Value   float64 `csv:"value"`
+	}
+
+	d := []csvData{
+		{Comment: "=HYPERLINK(\"http://example.com\")", Value: -1.5},
+		{Comment: "@SUM(A1:A2)", Value: 1},
+		{Comment: "+1-2", Value: 0},
+		{Comment: "\tcmd", Value: 0},
+		{Comment: "safe", Value: 0},
+	}
+
+	t.Run("None", func(t *testing.T) {
+		encoded, err := csvutil.MarshalString(d[:1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if encoded != "comment,value\n\"=HYPERLINK(\"\"http://example.com\"\")\",-1.5\n" {
+			t.Fatal(encoded)
+		}
+	})
+
+	t.Run("Escape", func(t *testing.T) {
+		out := new(strings.Builder)
+		enc := csvutil.NewEncoder(out)
+		enc.FormulaProtection = csvutil.FormulaProtectionEscape
+		if err := enc.Encode(d); err != nil {
+			t.Fatal(err)
+		}
+
+		const want = "comment,value\n" +
+			"\"'=HYPERLINK(\"\"http://example.com\"\")\",-1.5\n" +
+			"'@SUM(A1:A2),1\n" +
+			"'+1-2,0\n" +
+			"'\tcmd,0\n" +
+			"safe,0\n"
+		if out.String() != want {
+			t.Fatalf("want: %q got: %q", want, out.String())
+		}
+
+		var decoded []csvData
+		dec := csvutil.NewDecoder(strings.NewReader(out.String()))
+		dec.UnescapeFormula = true
+		if err := dec.Decode(&decoded); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(decoded, d) {
+			t.Fatal(decoded)
+		}
+	})
+
+	t.Run("EscapeRoundTrip", func(t *testing.T) {
+		cells := []csvData{
+			{Comment: "'=SUM(A1)"},
+			{Comment: "''=SUM(A1)"},
+			{Comment: "'@x"},
+			{Comment: "'quoted"},
+			{Comment: "'"},
+			{Comment: "=SUM(A1)"},
+		}
+
+		out := new(strings.Builder)
+		enc := csvutil.NewEncoder(out)
+		enc.FormulaProtection = csvutil.FormulaProtectionEscape
+		if err := enc.Encode(cells); err != nil {
+			t.Fatal(err)
+		}
+
+		const want = "comment,value\n" +
+			"''=SUM(A1),0\n" +
+			"'''=SUM(A1),0\n" +
+			"''@x,0\n" +
+			"'quoted,0\n" +
+			"',0\n" +
+			"'=SUM(A1),0\n"
+		if out.String() != want {
+			t.Fatalf("want: %q got: %q", want, out.String())
+		}
+
+		var decoded []csvData
+		dec := csvutil.NewDecoder(strings.NewReader(out.String()))
+		dec.UnescapeFormula = true
+		if err := dec.Decode(&decoded); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(decoded, cells) {
+			t.Fatal(decoded)
+		}
+	})
+
+	t.Run("Reject", func(t *testing.T) {
+		enc := csvutil.NewEncoder(new(strings.Builder))
+		enc.FormulaProtection = csvutil.FormulaProtectionReject
+		if err := enc.Encode(d[1:]); err == nil {
+			t.Fatal("the formula must be rejected")
+		}
+
+		enc = csvutil.NewEncoder(new(strings.Builder))
+		enc.FormulaProtection = csvutil.FormulaProtectionReject
+		if err := enc.Encode(d[4:]); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package csvutil
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+)
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// EmptyCell is the policy of Decoder for the empty cell.
+// The policy is applied after the `default` tag option, and the cell of nil is decoded as nil regardless of the policy.
+type EmptyCell int
+
+const (
+	// EmptyCellDecode is decodes the empty cell as it is. The empty cell is valid for string, but it is an error for number, bool and so on.
+	EmptyCellDecode EmptyCell = iota
+
+	// EmptyCellZero is sets the zero value to the field of the empty cell.
+	EmptyCellZero
+
+	// EmptyCellError is treats the empty cell as an error for all types including string.
+	EmptyCellError
+)
+
+var (
+	errEmptyCell = fmt.Errorf("the cell is empty")
+)
+
+func parseEmptyCell(s string) (EmptyCell, error) {
+	switch s {
+	case "decode":
+		return EmptyCellDecode, nil
+	case "zero":
+		return EmptyCellZero, nil
+	case "error":
+		return EmptyCellError, nil
+	default:
+		return EmptyCellDecode, fmt.Errorf("invalid empty option: %q", s)
+	}
+}
+
+// nilString returns the representation of nil of the field.
+func (f *fieldFormat) nilString(defaultNil string) string {
+	if f.nilStr != nil {
+		return *f.nilStr
+	}
+	return defaultNil
+}
+
+// emptyCellPolicy returns the policy of the empty cell of the field.
+func (f *fieldFormat) emptyCellPolicy(defaultPolicy EmptyCell) EmptyCell {
+	if f.emptyCell != nil {
+		return *f.emptyCell
+	}
+	return defaultPolicy
+}
+
+// isNullableType returns whether t is the nullable type like sql.NullString.
+// The nullable type is a struct which has the exported value field and the `Valid bool` field,
+// and its pointer implements sql.Scanner and driver.Valuer, so that the ordinary struct of the same shape is flattened.
+// The nil is decoded as Valid false, and the other value is decoded into the value field.
+func isNullableType(t reflect.Type) bool {
+	_, ok := nullableValueIndex(t)
+	return ok
+}
+
+// nullableValueIndex returns the index of the value field of the nullable type.
+func nullableValueIndex(t reflect.Type) (int, bool) {
+
+	if t.Kind() != reflect.Struct || t.NumField() != 2 {
+		return 0, false
+	}
+	if pt := reflect.PtrTo(t); !pt.Implements(scannerType) || !pt.Implements(valuerType) {
+		return 0, false
+	}
+
+	valid, ok := t.FieldByName("Valid")
+	if !ok || valid.Type.Kind() != reflect.Bool || len(valid.Index) != 1 {
+		return 0, false
+	}
+
+	index := 1 - valid.Index[0]
+	if t.Field(index).PkgPath != "" {
+		return 0, false
+	}
+	return index, true
+}
+
+// decodeNullable is decodes the cell into the nullable type.
+func (d *Decoder) decodeNullable(raw string, rv reflect.Value, f *field) error {
+
+	rv.Set(reflect.Zero(rv.Type()))
+	if raw == f.format.nilString(d.Nil) {
+		return nil
+	}
+
+	index, _ := nullableValueIndex(rv.Type())
+	if err := d.decodeValue(raw, rv.Field(index), f); err != nil {
+		return err
+	}
+	rv.FieldByName("Valid").SetBool(true)
+	return nil
+}
+
+// encodeNullable is encodes the nullable type into a cell.
+func (e *Encoder) encodeNullable(rv reflect.Value, f *field) (string, error) {
+
+	if !rv.FieldByName("Valid").Bool() {
+		return f.format.nilString(e.Nil), nil
+	}
+
+	index, _ := nullableValueIndex(rv.Type())
+	return e.encodeValue(rv.Field(index), f)
+}
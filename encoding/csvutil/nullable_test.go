@@ -0,0 +1,173 @@
+package csvutil_test
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.nanasi880.dev/x/encoding/csvutil"
+)
+
+func TestDecoder_Decode_DefaultAndNil(t *testing.T) {
+
+	type csvData struct {
+		Count   int     `csv:"count,default=10"`
+		Name    string  `csv:"name,default=unknown"`
+		Ratio   *int    `csv:"ratio,nil=NULL"`
+		Comment *string `csv:"comment,nil=-,default=-"`
+	}
+
+	const input = "count,name,ratio,comment\n" +
+		"1,foo,NULL,\n" +
+		",,5,bar\n"
+
+	var decoded []csvData
+	if err := csvutil.UnmarshalString(input, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	ratio, comment := 5, "bar"
+	want := []csvData{
+		{Count: 1, Name: "foo", Ratio: nil, Comment: nil},
+		{Count: 10, Name: "unknown", Ratio: &ratio, Comment: &comment},
+	}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Fatal(decoded)
+	}
+
+	encoded, err := csvutil.MarshalString(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded != "count,name,ratio,comment\n1,foo,NULL,-\n10,unknown,5,bar\n" {
+		t.Fatal(encoded)
+	}
+}
+
+func TestDecoder_Decode_EmptyCell(t *testing.T) {
+
+	type csvData struct {
+		ID    int     `csv:"id"`
+		Score float64 `csv:"score"`
+		Name  string  `csv:"name"`
+		Note  *string `csv:"note"`
+	}
+
+	const input = "id,score,name,note\n1,,,\n"
+
+	t.Run("Decode", func(t *testing.T) {
+		var decoded []csvData
+		if err := csvutil.UnmarshalString(input, &decoded); err == nil {
+			t.Fatal("the empty cell of float64 must be an error")
+		}
+	})
+
+	t.Run("Zero", func(t *testing.T) {
+		dec := csvutil.NewDecoder(strings.NewReader(input))
+		dec.EmptyCell = csvutil.EmptyCellZero
+
+		var decoded []csvData
+		if err := dec.Decode(&decoded); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(decoded, []csvData{{ID: 1}}) {
+			t.Fatal(decoded)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		type csvData struct {
+			ID   int     `csv:"id"`
+			Name string  `csv:"name,empty=error"`
+			Note *string `csv:"note"`
+		}
+
+		dec := csvutil.NewDecoder(strings.NewReader("id,name,note\n1,,\n"))
+		dec.EmptyCell = csvutil.EmptyCellZero
+
+		var decoded []csvData
+		err := dec.Decode(&decoded)
+
+		var decodeErr *csvutil.DecodeError
+		if !errors.As(err, &decodeErr) || decodeErr.Header != "name" {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestDecoder_Decode_Nullable(t *testing.T) {
+
+	type csvData struct {
+		Name  sql.NullString  `csv:"name"`
+		Count sql.NullInt64   `csv:"count"`
+		Score sql.NullFloat64 `csv:"score,format=%.1f"`
+		OK    sql.NullBool    `csv:"ok,true=Y,false=N"`
+		Date  sql.NullTime    `csv:"date,layout=2006-01-02"`
+	}
+
+	const input = "name,count,score,ok,date\n" +
+		"foo,1,1.5,Y,2021-02-03\n" +
+		",,,,\n"
+
+	var decoded []csvData
+	if err := csvutil.UnmarshalString(input, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []csvData{
+		{
+			Name:  sql.NullString{String: "foo", Valid: true},
+			Count: sql.NullInt64{Int64: 1, Valid: true},
+			Score: sql.NullFloat64{Float64: 1.5, Valid: true},
+			OK:    sql.NullBool{Bool: true, Valid: true},
+			Date:  sql.NullTime{Time: time.Date(2021, 2, 3, 0, 0, 0, 0, time.UTC), Valid: true},
+		},
+		{},
+	}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Fatal(decoded)
+	}
+
+	encoded, err := csvutil.MarshalString(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded != input {
+		t.Fatal(encoded)
+	}
+}
+
+func TestEncoder_Encode_NotNullable(t *testing.T) {
+
+	type check struct {
+		Reason string `csv:"reason"`
+		Valid  bool   `csv:"valid"`
+	}
+	type csvData struct {
+		ID    int   `csv:"id"`
+		Check check `csv:"check"`
+	}
+
+	// the struct of the same shape as sql.NullString is flattened unless it implements sql.Scanner and driver.Valuer
+	data := []csvData{{ID: 1, Check: check{Reason: "bad", Valid: false}}}
+
+	encoded, err := csvutil.MarshalString(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "id,check.reason,check.valid\n1,bad,false\n"
+	if encoded != want {
+		t.Fatalf("want: %q got: %q", want, encoded)
+	}
+
+	var decoded []csvData
+	if err := csvutil.UnmarshalString(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, data) {
+		t.Fatal(decoded)
+	}
+}
@@ -0,0 +1,401 @@
+package csvutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+const defaultChunkSize = 1 << 20
+
+var (
+	errParallelAfterDecode = fmt.Errorf("DecodeParallel cannot be used after Decode or DecodeNext")
+)
+
+// chunk is the record-aligned part of the input.
+type chunk struct {
+	seq  int    // seq is the sequence number of the chunk.
+	line int    // line is the line number of the first line of the chunk.
+	data []byte // data is the whole records.
+}
+
+// chunkResult is the result of decoding a chunk.
+type chunkResult struct {
+	seq    int
+	values reflect.Value // values is the slice of the decoded records.
+	err    error
+}
+
+// chunkReader splits the input into the record-aligned chunks.
+// The line breaks in the quoted fields are not treated as the end of the record.
+type chunkReader struct {
+	r       *bufio.Reader
+	comment rune
+	seq     int
+	line    int
+}
+
+func newChunkReader(r io.Reader, comment rune) *chunkReader {
+	return &chunkReader{
+		r:       bufio.NewReader(r),
+		comment: comment,
+		seq:     0,
+		line:    1,
+	}
+}
+
+// next reads the records until the size of the chunk reaches size or the number of the records reaches records.
+// If records is 0, the number of the records is not limited. The blank lines and the comments are not counted as the record.
+// At the end of the input, next returns io.EOF with the empty chunk.
+func (c *chunkReader) next(size int, records int) (chunk, error) {
+
+	var (
+		data        []byte
+		inQuote     = false
+		skipLine    = false // the comment or the blank line
+		recordCount = 0
+	)
+
+	for {
+		line, err := c.r.ReadSlice('\n')
+		if err != nil && err != bufio.ErrBufferFull && err != io.EOF {
+			return chunk{}, err
+		}
+
+		if !inQuote && (len(data) == 0 || data[len(data)-1] == '\n') {
+			isComment := c.comment != 0 && bytes.HasPrefix(line, []byte(string(c.comment)))
+			skipLine = isComment || len(bytes.TrimRight(line, "\r\n")) == 0
+		}
+		data = append(data, line...)
+
+		if err == bufio.ErrBufferFull {
+			// the rest of the line is read on the next iteration
+			if !skipLine && bytes.Count(line, []byte{'"'})%2 == 1 {
+				inQuote = !inQuote
+			}
+			continue
+		}
+
+		if !skipLine {
+			if bytes.Count(line, []byte{'"'})%2 == 1 {
+				inQuote = !inQuote
+			}
+			if !inQuote && len(line) > 0 {
+				recordCount++
+			}
+		}
+
+		if err == io.EOF || (!inQuote && recordCount > 0 && (len(data) >= size || (records > 0 && recordCount >= records))) {
+			if len(data) == 0 {
+				return chunk{}, io.EOF
+			}
+
+			ch := chunk{
+				seq:  c.seq,
+				line: c.line,
+				data: data,
+			}
+			c.seq++
+			c.line += bytes.Count(data, []byte{'\n'})
+			return ch, nil
+		}
+	}
+}
+
+// DecodeParallel is decodes a slice of a structure from CSV data like Decode, but the records are decoded on multiple goroutines.
+// The input is split into the chunks of the whole records, and the line breaks in the quoted fields are handled correctly unless LazyQuotes is true.
+// The order of the records in out is the same as the input.
+// DecodeParallel reads the whole input, and it cannot be used after Decode or DecodeNext.
+func (d *Decoder) DecodeParallel(out interface{}) error {
+
+	if out == nil {
+		return fmt.Errorf("nil")
+	}
+
+	sliceElemType, err := d.getValueType(out)
+	if err != nil {
+		return err
+	}
+
+	var (
+		outSlice = reflect.ValueOf(out).Elem()
+		result   = reflect.MakeSlice(outSlice.Type(), 0, 0)
+		errs     DecodeErrors
+	)
+
+	err = d.decodeParallel(sliceElemType, true, func(r chunkResult) error {
+		if rowErrs, ok := r.err.(DecodeErrors); ok {
+			errs = append(errs, rowErrs...)
+		} else if r.err != nil {
+			return r.err
+		}
+		result = reflect.AppendSlice(result, r.values)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	outSlice.Set(result)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// DecodeParallelFunc is decodes CSV data on multiple goroutines like DecodeParallel, and calls fn for each record.
+// The v is the pointer of the type to decode, the same as DecodeNext. It is used only to determine the type, and it is not modified.
+// The fn receives the new pointer of struct (or Record), or the new map for each record. The fn is called on the goroutine of the caller.
+// If Unordered is false, fn is called in the order of the input. Otherwise, fn is called in the order the chunks are decoded.
+// If fn returns an error, DecodeParallelFunc stops decoding and returns the error.
+// If Lenient is true, the records that failed to decode are skipped and all errors are returned as DecodeErrors.
+func (d *Decoder) DecodeParallelFunc(v interface{}, fn func(v interface{}) error) error {
+
+	if v == nil || fn == nil {
+		return fmt.Errorf("nil")
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errInvalidDecodeNextType
+	}
+	elemType := rv.Elem().Type()
+	if elemType.Kind() != reflect.Struct && !isDynamicType(elemType) {
+		return errInvalidDecodeNextType
+	}
+	if elemType.Kind() == reflect.Struct {
+		elemType = reflect.PtrTo(elemType)
+	}
+
+	var errs DecodeErrors
+	err := d.decodeParallel(elemType, !d.Unordered, func(r chunkResult) error {
+		if rowErrs, ok := r.err.(DecodeErrors); ok {
+			errs = append(errs, rowErrs...)
+		} else if r.err != nil {
+			return r.err
+		}
+
+		for i := 0; i < r.values.Len(); i++ {
+			if err := fn(r.values.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// decodeParallel is decodes the chunks of the input on the workers, and calls emit for each result on the goroutine of the caller.
+// If ordered is true, emit is called in the order of the input.
+func (d *Decoder) decodeParallel(elemType reflect.Type, ordered bool, emit func(r chunkResult) error) error {
+
+	if d.fieldIndexType != nil || d.header != nil {
+		return errParallelAfterDecode
+	}
+
+	chunks := newChunkReader(d.src, d.Comment)
+
+	// the header is decoded in advance, so that the header errors are returned before any records are decoded.
+	// The mapping of the columns is computed only once and shared by the workers.
+	var header chunk
+	if d.UseHeader {
+		var err error
+		header, err = chunks.next(0, 1)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	prepared := d.clone(header, d.FieldsPerRecord)
+	if _, err := prepared.getFieldIndex(elemType); err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	d.header = prepared.header
+	d.fieldIndex = prepared.fieldIndex
+	d.fieldIndexType = prepared.fieldIndexType
+	d.plan = prepared.plan
+
+	// the number of the fields is determined by the first record of the whole input as the same as Decode,
+	// instead of the first record of each chunk.
+	var (
+		fieldsPerRecord = d.FieldsPerRecord
+		first           *chunk
+	)
+	if fieldsPerRecord == 0 {
+		if d.UseHeader {
+			fieldsPerRecord = len(d.header)
+		} else {
+			ch, err := chunks.next(0, 1)
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			// the error of the first record is reported by the worker
+			if record, err := d.clone(ch, 0).read(); err == nil {
+				fieldsPerRecord = len(record)
+			}
+			first = &ch
+		}
+	}
+
+	workers := d.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	chunkSize := d.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	var (
+		jobs    = make(chan chunk, workers)
+		results = make(chan chunkResult, workers)
+		done    = make(chan struct{})
+		wg      sync.WaitGroup
+	)
+	defer func() {
+		close(done)
+		// drain the results to stop the workers
+		for range results {
+		}
+	}()
+
+	// the header may have consumed the first sequence number
+	nextSeq := chunks.seq
+	if first != nil {
+		nextSeq = first.seq
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+
+		if first != nil {
+			select {
+			case jobs <- *first:
+			case <-done:
+				return
+			}
+		}
+
+		for {
+			ch, err := chunks.next(chunkSize, 0)
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				select {
+				case results <- chunkResult{seq: chunks.seq, err: err}:
+				case <-done:
+				}
+				return
+			}
+
+			select {
+			case jobs <- ch:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	sliceType := reflect.SliceOf(elemType)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for ch := range jobs {
+				values := reflect.New(sliceType).Elem()
+				err := d.clone(ch, fieldsPerRecord).decodeRows(values, elemType)
+
+				select {
+				case results <- chunkResult{seq: ch.seq, values: values, err: err}:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	pending := make(map[int]chunkResult)
+	for r := range results {
+		if !ordered {
+			if err := emit(r); err != nil {
+				return err
+			}
+			continue
+		}
+
+		pending[r.seq] = r
+		for {
+			r, ok := pending[nextSeq]
+			if !ok {
+				break
+			}
+			delete(pending, nextSeq)
+			nextSeq++
+
+			if err := emit(r); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// clone returns the Decoder which has the same options as d and reads the chunk.
+// The number of the fields of the records is fieldsPerRecord instead of FieldsPerRecord.
+// The line numbers of the errors are adjusted to the position of the chunk in the whole input.
+func (d *Decoder) clone(ch chunk, fieldsPerRecord int) *Decoder {
+
+	reader := csv.NewReader(bytes.NewReader(ch.data))
+
+	clone := *d
+	clone.FieldsPerRecord = fieldsPerRecord
+	clone.r = reader
+	clone.src = nil
+	clone.sections = nil
+	clone.lineOffset = ch.line - 1
+	clone.setupReader()
+
+	return &clone
+}
+
+// adjustParseError adjusts the line numbers of *csv.ParseError by the offset of the chunk.
+func (d *Decoder) adjustParseError(err error) error {
+
+	var parseErr *csv.ParseError
+	if d.lineOffset > 0 && errors.As(err, &parseErr) {
+		parseErr.StartLine += d.lineOffset
+		parseErr.Line += d.lineOffset
+	}
+	return err
+}
@@ -0,0 +1,256 @@
+package csvutil_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"go.nanasi880.dev/x/encoding/csvutil"
+)
+
+type parallelData struct {
+	ID    int     `csv:"id"`
+	Name  string  `csv:"name"`
+	Score float64 `csv:"score"`
+}
+
+func makeParallelData(n int) []parallelData {
+	d := make([]parallelData, 0, n)
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("name %d", i)
+		if i%7 == 0 {
+			// the quoted line breaks must not split the record
+			name = fmt.Sprintf("multi\nline \"%d\"\n", i)
+		}
+		d = append(d, parallelData{ID: i, Name: name, Score: float64(i) / 4})
+	}
+	return d
+}
+
+func TestDecoder_DecodeParallel(t *testing.T) {
+
+	want := makeParallelData(1000)
+	encoded, err := csvutil.MarshalString(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded = "# comment \"\n\n" + encoded
+
+	for _, chunkSize := range []int{0, 1, 64, 1000} {
+		dec := csvutil.NewDecoder(strings.NewReader(encoded))
+		dec.Comment = '#'
+		dec.Workers = 4
+		dec.ChunkSize = chunkSize
+
+		var got []*parallelData
+		if err := dec.DecodeParallel(&got); err != nil {
+			t.Fatal(chunkSize, err)
+		}
+		if len(got) != len(want) {
+			t.Fatal(chunkSize, len(got))
+		}
+		for i := range want {
+			if *got[i] != want[i] {
+				t.Fatal(chunkSize, i, *got[i])
+			}
+		}
+	}
+}
+
+func TestDecoder_DecodeParallelFunc(t *testing.T) {
+
+	want := makeParallelData(500)
+	encoded, err := csvutil.MarshalString(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Ordered", func(t *testing.T) {
+		dec := csvutil.NewDecoder(strings.NewReader(encoded))
+		dec.ChunkSize = 100
+
+		var got []parallelData
+		err := dec.DecodeParallelFunc(new(parallelData), func(v interface{}) error {
+			got = append(got, *v.(*parallelData))
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatal(got)
+		}
+	})
+
+	t.Run("Unordered", func(t *testing.T) {
+		dec := csvutil.NewDecoder(strings.NewReader(encoded))
+		dec.ChunkSize = 100
+		dec.Unordered = true
+
+		var got []parallelData
+		err := dec.DecodeParallelFunc(new(parallelData), func(v interface{}) error {
+			got = append(got, *v.(*parallelData))
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Slice(got, func(i, j int) bool { return got[i].ID < got[j].ID })
+		if !reflect.DeepEqual(got, want) {
+			t.Fatal(got)
+		}
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		dec := csvutil.NewDecoder(strings.NewReader(encoded))
+		dec.ChunkSize = 100
+
+		errStop := errors.New("stop")
+		count := 0
+		err := dec.DecodeParallelFunc(new(parallelData), func(v interface{}) error {
+			count++
+			if count == 10 {
+				return errStop
+			}
+			return nil
+		})
+		if err != errStop || count != 10 {
+			t.Fatal(err, count)
+		}
+	})
+
+	t.Run("Record", func(t *testing.T) {
+		dec := csvutil.NewDecoder(strings.NewReader(encoded))
+		dec.ChunkSize = 100
+
+		count := 0
+		err := dec.DecodeParallelFunc(new(map[string]string), func(v interface{}) error {
+			if v.(map[string]string)["id"] != fmt.Sprint(count) {
+				t.Fatal(v)
+			}
+			count++
+			return nil
+		})
+		if err != nil || count != len(want) {
+			t.Fatal(err, count)
+		}
+	})
+}
+
+func TestDecoder_DecodeParallel_Error(t *testing.T) {
+
+	const input = "id,name,score\n" +
+		"1,a,1\n" +
+		"2,\"b\nb\",2\n" +
+		"x,c,3\n" +
+		"4,d,y\n" +
+		"5,e,5\n"
+
+	t.Run("Strict", func(t *testing.T) {
+		dec := csvutil.NewDecoder(strings.NewReader(input))
+		dec.ChunkSize = 1
+
+		var got []parallelData
+		err := dec.DecodeParallel(&got)
+
+		var decodeErr *csvutil.DecodeError
+		if !errors.As(err, &decodeErr) {
+			t.Fatal(err)
+		}
+		if decodeErr.Line != 5 || decodeErr.Header != "id" {
+			t.Fatal(decodeErr)
+		}
+	})
+
+	t.Run("Lenient", func(t *testing.T) {
+		dec := csvutil.NewDecoder(strings.NewReader(input))
+		dec.ChunkSize = 1
+		dec.Lenient = true
+
+		var got []parallelData
+		err := dec.DecodeParallel(&got)
+
+		var errs csvutil.DecodeErrors
+		if !errors.As(err, &errs) || len(errs) != 2 {
+			t.Fatal(err)
+		}
+		if errs[0].Line != 5 || errs[1].Line != 6 {
+			t.Fatal(errs)
+		}
+		if len(got) != 3 || got[2].ID != 5 {
+			t.Fatal(got)
+		}
+	})
+}
+
+func TestDecoder_DecodeParallel_FieldsPerRecord(t *testing.T) {
+
+	type csvData struct {
+		A int `csv:"a"`
+		B int `csv:"b"`
+	}
+
+	testSuites := []struct {
+		input     string
+		useHeader bool
+	}{
+		{input: "a,b\n1,2,3\n4,5,6\n", useHeader: true},
+		{input: "1,2\n3,4\n5,6,7\n8,9,10\n", useHeader: false},
+	}
+
+	for i, suite := range testSuites {
+		dec := csvutil.NewDecoder(strings.NewReader(suite.input))
+		dec.UseHeader = suite.useHeader
+		var sequential []csvData
+		want := dec.Decode(&sequential)
+
+		dec = csvutil.NewDecoder(strings.NewReader(suite.input))
+		dec.UseHeader = suite.useHeader
+		dec.ChunkSize = 1
+		var parallel []csvData
+		got := dec.DecodeParallel(&parallel)
+
+		if want == nil || got == nil || want.Error() != got.Error() {
+			t.Fatalf("suite:%d want: %v got: %v", i, want, got)
+		}
+	}
+}
+
+func benchmarkDecodeInput(b *testing.B) string {
+	encoded, err := csvutil.MarshalString(makeParallelData(100000))
+	if err != nil {
+		b.Fatal(err)
+	}
+	return encoded
+}
+
+func BenchmarkDecoder_Decode(b *testing.B) {
+
+	input := benchmarkDecodeInput(b)
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var out []parallelData
+		if err := csvutil.NewDecoder(strings.NewReader(input)).Decode(&out); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecoder_DecodeParallel(b *testing.B) {
+
+	input := benchmarkDecodeInput(b)
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var out []parallelData
+		if err := csvutil.NewDecoder(strings.NewReader(input)).DecodeParallel(&out); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,356 @@
+package csvutil
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"go.nanasi880.dev/x/unsafe/unsafeutil"
+)
+
+var (
+	stringerType  = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	errorType     = reflect.TypeOf((*error)(nil)).Elem()
+	formatterType = reflect.TypeOf((*fmt.Formatter)(nil)).Elem()
+)
+
+var (
+	encodePlanCache   sync.Map // encodePlanCache is the cache of *encodePlan without the buffers, keyed by planKey.
+	decodeFieldsCache sync.Map // decodeFieldsCache is the cache of the fields of Decoder, keyed by planKey.
+)
+
+// planKey is the key of the cached plans. The plans depend on the type, the options and the default converters.
+// The plans of Encoder and Decoder which have their own converters are not cached.
+type planKey struct {
+	typ        reflect.Type
+	separator  string // separator is HeaderSeparator.
+	columns    string // columns is Columns of Encoder joined by NUL.
+	hasColumns bool   // hasColumns is whether Columns of Encoder is not nil.
+	converters uint64 // converters is the version of the default converters.
+}
+
+// encodePlan is the cached plan to encode the struct type.
+// The fields and columns are shared by the encoders of the same planKey, and the buffers are owned by each Encoder.
+type encodePlan struct {
+	fields  []field // fields is the columns of the header.
+	columns []encodeColumn
+	buf     []byte   // buf is the reused buffer of the cells.
+	ends    []int    // ends is the end offsets of the cells in buf.
+	record  []string // record is the reused record passed to the csv.Writer.
+}
+
+// encodeColumn is the plan to encode a column.
+type encodeColumn struct {
+	field field
+	op    encodeOp
+}
+
+// encodeOp is how to encode the value of the column.
+type encodeOp int
+
+const (
+	encodeOpField encodeOp = iota // the value is encoded by encodeField.
+	encodeOpBasic                 // the value is appended by appendBasic without boxing.
+	encodeOpTime                  // the time.Time is appended by appendTime.
+)
+
+// decodePlan is the cached plan to decode the records into the struct type.
+type decodePlan struct {
+	columns []*decodeColumn // columns is indexed by the csv column. nil if the column is not mapped to any field.
+}
+
+// decodeColumn is the plan to decode a column.
+type decodeColumn struct {
+	field field
+	fast  bool // If true, the value is decoded by decodeKind directly. Otherwise, decodeField is used.
+}
+
+// encodePlanOf returns the plan to encode the struct type t.
+// The plan is cached by the type and the options, unless the Encoder has its own converters.
+func (e *Encoder) encodePlanOf(t reflect.Type) (*encodePlan, error) {
+
+	key := planKey{
+		typ:        t,
+		separator:  e.HeaderSeparator,
+		columns:    strings.Join(e.Columns, "\x00"),
+		hasColumns: e.Columns != nil,
+		converters: convertersVersion(),
+	}
+	cacheable := len(e.encodeFuncs) == 0
+	if cacheable {
+		if cached, ok := encodePlanCache.Load(key); ok {
+			return cached.(*encodePlan).withBuffers(), nil
+		}
+	}
+
+	fields, err := typeFields(t, e.HeaderSeparator, e.encodeFuncs.has)
+	if err != nil {
+		return nil, err
+	}
+	if e.Columns != nil {
+		fields, err = selectFields(fields, e.Columns)
+	} else {
+		fields, err = orderFields(fields)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	plan := e.newEncodePlan(fields)
+	if cacheable {
+		encodePlanCache.Store(key, plan)
+	}
+	return plan.withBuffers(), nil
+}
+
+// withBuffers returns the copy of the plan which has its own buffers.
+func (p *encodePlan) withBuffers() *encodePlan {
+	return &encodePlan{
+		fields:  p.fields,
+		columns: p.columns,
+		buf:     nil,
+		ends:    make([]int, 0, len(p.columns)),
+		record:  make([]string, 0, len(p.columns)),
+	}
+}
+
+// newEncodePlan returns the plan of the fields without the buffers.
+func (e *Encoder) newEncodePlan(fields []field) *encodePlan {
+
+	plan := &encodePlan{
+		fields:  fields,
+		columns: make([]encodeColumn, len(fields)),
+	}
+	for i, f := range fields {
+		op := encodeOpField
+		switch {
+		case f.kind != fieldKindValue:
+		case f.format.format == "" && e.isBasicType(f.typ):
+			op = encodeOpBasic
+		case f.typ == timeType && !e.encodeFuncs.has(timeType):
+			op = encodeOpTime
+		}
+		plan.columns[i] = encodeColumn{
+			field: f,
+			op:    op,
+		}
+	}
+	return plan
+}
+
+// isBasicType returns whether the value of t (or the pointer of t) is encoded as the same as fmt.Sprint.
+// The types which have the converter or the methods used by the encoding are not basic.
+func (e *Encoder) isBasicType(t reflect.Type) bool {
+
+	if t == nil {
+		return false
+	}
+	if t.Kind() == reflect.Ptr {
+		if e.encodeFuncs.has(t) {
+			return false
+		}
+		t = t.Elem()
+	}
+	if e.encodeFuncs.has(t) {
+		return false
+	}
+
+	return isBasicKind(t.Kind()) && !implementsAny(t, marshalerType, textMarshalerType, stringerType, errorType, formatterType)
+}
+
+// encode returns the record of the struct value v.
+// The returned record refers to the reused buffer, so it is valid until the next call.
+func (p *encodePlan) encode(e *Encoder, v reflect.Value) ([]string, error) {
+
+	buf, ends := p.buf[:0], p.ends[:0]
+	for i := range p.columns {
+		c := &p.columns[i]
+
+		fv, ok := fieldByIndex(v, c.field.index)
+		switch {
+		case !ok:
+			// the nested struct is nil
+			buf = append(buf, c.field.format.nilString(e.Nil)...)
+		case c.op == encodeOpBasic:
+			buf = e.appendBasic(buf, fv, &c.field)
+		case c.op == encodeOpTime && e.canAppendTime(fv, &c.field):
+			buf = e.appendTime(buf, fv, &c.field)
+		default:
+			encoded, err := e.encodeField(fv, &c.field)
+			if err != nil {
+				return nil, err
+			}
+			buf = append(buf, encoded...)
+		}
+		ends = append(ends, len(buf))
+	}
+	p.buf, p.ends = buf, ends
+
+	// the cells refer to buf without copying, since buf is not modified until the next record
+	record, start := p.record[:0], 0
+	for _, end := range ends {
+		record = append(record, unsafeutil.BytesToString(buf[start:end]))
+		start = end
+	}
+	p.record = record
+
+	return record, nil
+}
+
+// appendBasic appends the value of the basic type to buf.
+func (e *Encoder) appendBasic(buf []byte, rv reflect.Value, f *field) []byte {
+
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return append(buf, f.format.nilString(e.Nil)...)
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		if f.format.trueStr != nil || f.format.falseStr != nil {
+			return append(buf, f.format.formatBool(rv.Bool())...)
+		}
+		return strconv.AppendBool(buf, rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.AppendInt(buf, rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.AppendUint(buf, rv.Uint(), 10)
+	case reflect.Float32:
+		return strconv.AppendFloat(buf, rv.Float(), 'g', -1, 32)
+	case reflect.Float64:
+		return strconv.AppendFloat(buf, rv.Float(), 'g', -1, 64)
+	default:
+		return append(buf, rv.String()...)
+	}
+}
+
+// canAppendTime returns whether the time.Time can be appended by appendTime.
+// time.Time.MarshalText is used by default, and it fails if the year is out of range.
+func (e *Encoder) canAppendTime(rv reflect.Value, f *field) bool {
+	if f.format.isTime(timeType, e.TimeZone) {
+		return true
+	}
+	year := timeValue(rv).Year()
+	return year >= 0 && year <= 9999
+}
+
+// appendTime appends the time.Time to buf.
+func (e *Encoder) appendTime(buf []byte, rv reflect.Value, f *field) []byte {
+	t := timeValue(rv)
+	if loc := f.format.timeLocation(e.TimeZone); loc != nil {
+		t = t.In(loc)
+	}
+	return t.AppendFormat(buf, f.format.timeLayout())
+}
+
+// timeValue returns the time.Time of rv without boxing if possible.
+func timeValue(rv reflect.Value) time.Time {
+	if rv.CanAddr() {
+		return *(*time.Time)(unsafe.Pointer(rv.UnsafeAddr()))
+	}
+	return rv.Interface().(time.Time)
+}
+
+// typeFields returns the fields of the struct type t.
+// The fields are cached by the type and the options, unless the Decoder has its own converters.
+func (d *Decoder) typeFields(t reflect.Type) ([]field, error) {
+
+	key := planKey{
+		typ:        t,
+		separator:  d.HeaderSeparator,
+		converters: convertersVersion(),
+	}
+	cacheable := len(d.decodeFuncs) == 0
+	if cacheable {
+		if cached, ok := decodeFieldsCache.Load(key); ok {
+			return cached.([]field), nil
+		}
+	}
+
+	fields, err := typeFields(t, d.HeaderSeparator, d.decodeFuncs.has)
+	if err != nil {
+		return nil, err
+	}
+	if cacheable {
+		decodeFieldsCache.Store(key, fields)
+	}
+	return fields, nil
+}
+
+// newDecodePlan returns the plan of the mapping of the columns.
+func (d *Decoder) newDecodePlan(fieldIndex map[int]field) *decodePlan {
+
+	plan := &decodePlan{}
+	for i, f := range fieldIndex {
+		for len(plan.columns) <= i {
+			plan.columns = append(plan.columns, nil)
+		}
+		plan.columns[i] = &decodeColumn{
+			field: f,
+			fast:  f.kind == fieldKindValue && d.isBasicType(f.typ),
+		}
+	}
+	return plan
+}
+
+// isBasicType returns whether the value of t (or the pointer of t) is decoded by decodeKind without the converters.
+func (d *Decoder) isBasicType(t reflect.Type) bool {
+
+	if t == nil {
+		return false
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if d.decodeFuncs.has(t) || t == durationType {
+		return false
+	}
+
+	return isBasicKind(t.Kind()) && !implementsAny(t, unmarshalerType, textUnmarshalerType)
+}
+
+// decodeBasic is decodes the cell into the value of the basic type.
+func (d *Decoder) decodeBasic(raw string, rv reflect.Value, f *field) error {
+
+	raw, ok, err := d.decodeEmpty(raw, rv, f, rv.Kind() == reflect.Ptr)
+	if !ok {
+		return err
+	}
+
+	if rv.Kind() == reflect.Ptr {
+		rv.Set(reflect.New(rv.Type().Elem()))
+		rv = rv.Elem()
+	}
+	return d.decodeKind(raw, rv, f)
+}
+
+func isBasicKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64,
+		reflect.String:
+		return true
+	default:
+		return false
+	}
+}
+
+// implementsAny returns whether t or the pointer of t implements any of the interfaces.
+func implementsAny(t reflect.Type, interfaces ...reflect.Type) bool {
+	for _, typ := range []reflect.Type{t, reflect.PtrTo(t)} {
+		for _, i := range interfaces {
+			if typ.Implements(i) {
+				return true
+			}
+		}
+	}
+	return false
+}
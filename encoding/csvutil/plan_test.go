@@ -0,0 +1,246 @@
+package csvutil_test
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.nanasi880.dev/x/encoding/csvutil"
+)
+
+type planData struct {
+	ID       int64         `csv:"id"`
+	Name     string        `csv:"name"`
+	Age      uint8         `csv:"age"`
+	Score    float64       `csv:"score"`
+	Ratio    float32       `csv:"ratio"`
+	Active   bool          `csv:"active"`
+	Parent   *int          `csv:"parent"`
+	Interval time.Duration `csv:"interval"`
+	Created  time.Time     `csv:"created"`
+	Label    planLabel     `csv:"label"`
+}
+
+// planLabel has String method, which must be used by the encoder as fmt.Sprint does.
+type planLabel int
+
+func (l planLabel) String() string {
+	return fmt.Sprintf("label-%d", int(l))
+}
+
+func makePlanData(n int) []planData {
+	d := make([]planData, 0, n)
+	for i := 0; i < n; i++ {
+		v := planData{
+			ID:       int64(i) * 1000003,
+			Name:     fmt.Sprintf("name-%d", i),
+			Age:      uint8(i),
+			Score:    float64(i) / 3,
+			Ratio:    float32(i) / 7,
+			Active:   i%2 == 0,
+			Interval: time.Duration(i) * time.Second,
+			Created:  time.Date(2021, 1, 2, 3, 4, 5, i, time.UTC),
+			Label:    planLabel(i),
+		}
+		if i%3 == 0 {
+			parent := i - 1
+			v.Parent = &parent
+		}
+		d = append(d, v)
+	}
+	return d
+}
+
+func TestEncoder_Encode_Plan(t *testing.T) {
+
+	d := makePlanData(100)
+	encoded, err := csvutil.MarshalString(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the cells are the same as fmt.Sprint
+	lines := strings.Split(encoded, "\n")
+	for i, v := range d {
+		parent := ""
+		if v.Parent != nil {
+			parent = fmt.Sprint(*v.Parent)
+		}
+		want := strings.Join([]string{
+			fmt.Sprint(v.ID), v.Name, fmt.Sprint(v.Age), fmt.Sprint(v.Score), fmt.Sprint(v.Ratio),
+			fmt.Sprint(v.Active), parent, fmt.Sprint(v.Interval), v.Created.Format(time.RFC3339Nano), v.Label.String(),
+		}, ",")
+		if lines[i+1] != want {
+			t.Fatalf("want: %s got: %s", want, lines[i+1])
+		}
+	}
+}
+
+func TestDecoder_Decode_Plan(t *testing.T) {
+
+	d := makePlanData(100)
+	encoded, err := csvutil.MarshalString(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type decodeData struct {
+		ID       int64         `csv:"id"`
+		Name     string        `csv:"name"`
+		Age      uint8         `csv:"age"`
+		Score    float64       `csv:"score"`
+		Ratio    float32       `csv:"ratio"`
+		Active   bool          `csv:"active"`
+		Parent   *int          `csv:"parent"`
+		Interval time.Duration `csv:"interval"`
+		Created  time.Time     `csv:"created"`
+	}
+
+	var decoded []decodeData
+	if err := csvutil.UnmarshalString(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range d {
+		want := decodeData{
+			ID: v.ID, Name: v.Name, Age: v.Age, Score: v.Score, Ratio: v.Ratio,
+			Active: v.Active, Parent: v.Parent, Interval: v.Interval, Created: v.Created,
+		}
+		if !reflect.DeepEqual(decoded[i], want) {
+			t.Fatalf("want: %v got: %v", want, decoded[i])
+		}
+	}
+}
+
+func TestEncoder_Encode_PlanCache(t *testing.T) {
+
+	type inner struct {
+		B int `csv:"b"`
+	}
+	type cacheData struct {
+		A     planLabel `csv:"a"`
+		Inner inner     `csv:"inner"`
+	}
+	d := []cacheData{{A: 1, Inner: inner{B: 2}}}
+
+	encode := func(setup func(e *csvutil.Encoder)) string {
+		out := new(strings.Builder)
+		enc := csvutil.NewEncoder(out)
+		setup(enc)
+		if err := enc.Encode(d); err != nil {
+			t.Fatal(err)
+		}
+		return out.String()
+	}
+
+	// the cached plan must not be shared by the encoders of the different options
+	testSuites := []struct {
+		setup func(e *csvutil.Encoder)
+		want  string
+	}{
+		{setup: func(e *csvutil.Encoder) {}, want: "a,inner.b\nlabel-1,2\n"},
+		{setup: func(e *csvutil.Encoder) { e.HeaderSeparator = "_" }, want: "a,inner_b\nlabel-1,2\n"},
+		{setup: func(e *csvutil.Encoder) { e.Columns = []string{"inner.b"} }, want: "inner.b\n2\n"},
+		{setup: func(e *csvutil.Encoder) {
+			e.Register(reflect.TypeOf(planLabel(0)), func(v interface{}) (string, error) { return "own", nil })
+		}, want: "a,inner.b\nown,2\n"},
+		{setup: func(e *csvutil.Encoder) {}, want: "a,inner.b\nlabel-1,2\n"},
+	}
+	for i, suite := range testSuites {
+		if got := encode(suite.setup); got != suite.want {
+			t.Fatalf("suite:%d want: %q got: %q", i, suite.want, got)
+		}
+	}
+
+	// the default converter registered after the plan is cached is used
+	csvutil.RegisterEncodeFunc(reflect.TypeOf(planLabel(0)), func(v interface{}) (string, error) { return "default", nil })
+	defer csvutil.RegisterEncodeFunc(reflect.TypeOf(planLabel(0)), nil)
+	if got := encode(func(e *csvutil.Encoder) {}); got != "a,inner.b\ndefault,2\n" {
+		t.Fatal(got)
+	}
+}
+
+type benchmarkData struct {
+	ID      int64     `csv:"id"`
+	Name    string    `csv:"name"`
+	Age     uint8     `csv:"age"`
+	Score   float64   `csv:"score"`
+	Ratio   float32   `csv:"ratio"`
+	Active  bool      `csv:"active"`
+	Parent  *int      `csv:"parent"`
+	Created time.Time `csv:"created"`
+}
+
+func makeBenchmarkData(n int) []benchmarkData {
+	d := make([]benchmarkData, 0, n)
+	for _, v := range makePlanData(n) {
+		d = append(d, benchmarkData{
+			ID: v.ID, Name: v.Name, Age: v.Age, Score: v.Score, Ratio: v.Ratio,
+			Active: v.Active, Parent: v.Parent, Created: v.Created,
+		})
+	}
+	return d
+}
+
+func BenchmarkEncoder_Encode(b *testing.B) {
+
+	d := makeBenchmarkData(10000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := csvutil.NewEncoder(io.Discard).Encode(d); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecoder_Decode_Struct(b *testing.B) {
+
+	encoded, err := csvutil.MarshalString(makeBenchmarkData(10000))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(int64(len(encoded)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var decoded []benchmarkData
+		if err := csvutil.NewDecoder(strings.NewReader(encoded)).Decode(&decoded); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshal_SmallBatch(b *testing.B) {
+
+	d := makeBenchmarkData(10)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := csvutil.Marshal(d); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshal_SmallBatch(b *testing.B) {
+
+	encoded, err := csvutil.Marshal(makeBenchmarkData(10))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var decoded []benchmarkData
+		if err := csvutil.Unmarshal(encoded, &decoded); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
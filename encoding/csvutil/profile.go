@@ -0,0 +1,131 @@
+package csvutil
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// utf8BOM is the byte order mark of UTF-8.
+const utf8BOM = "\ufeff"
+
+// Profile is a set of the Encoder options for the consumers of CSV data.
+// All profiles emit the header, because the consumers expect the header to name the columns.
+// The options can be changed after SetProfile, e.g. UseHeader = false for the records appended to the existing file.
+type Profile int
+
+const (
+	// ProfileUnix is LF line endings without BOM, and the fields are quoted only if needed. It is the default of Encoder.
+	ProfileUnix Profile = iota
+
+	// ProfileRFC4180 is CRLF line endings without BOM, and the fields are quoted only if needed, as specified by RFC 4180.
+	ProfileRFC4180
+
+	// ProfileExcel is CRLF line endings with UTF-8 BOM, and all fields are quoted, for spreadsheet applications.
+	// The quoted fields are read as they are even if the field has the leading spaces or the separator of the locale.
+	ProfileExcel
+)
+
+// SetProfile is set the options of the profile to the Encoder.
+// The profile sets UseBOM, UseCRLF, QuoteAll and UseHeader. The other options are not changed.
+func (e *Encoder) SetProfile(p Profile) {
+	switch p {
+	case ProfileRFC4180:
+		e.UseBOM = false
+		e.UseCRLF = true
+		e.QuoteAll = false
+	case ProfileExcel:
+		e.UseBOM = true
+		e.UseCRLF = true
+		e.QuoteAll = true
+	default:
+		e.UseBOM = false
+		e.UseCRLF = false
+		e.QuoteAll = false
+	}
+	e.UseHeader = true
+}
+
+// writeBOM writes the BOM before the first record if UseBOM is true.
+func (e *Encoder) writeBOM() error {
+	if e.bomWritten {
+		return nil
+	}
+	e.bomWritten = true
+
+	if !e.UseBOM {
+		return nil
+	}
+	_, err := e.bw.WriteString(utf8BOM)
+	return err
+}
+
+// writeQuoted writes the record with all fields quoted.
+func (e *Encoder) writeQuoted(record []string) error {
+
+	for i, field := range record {
+		if i > 0 {
+			if _, err := e.bw.WriteRune(e.Comma); err != nil {
+				return err
+			}
+		}
+
+		field = strings.ReplaceAll(field, `"`, `""`)
+		if e.UseCRLF {
+			field = strings.ReplaceAll(field, "\r\n", "\n")
+			field = strings.ReplaceAll(field, "\n", "\r\n")
+		}
+		if _, err := e.bw.WriteString(`"` + field + `"`); err != nil {
+			return err
+		}
+	}
+
+	lineEnding := "\n"
+	if e.UseCRLF {
+		lineEnding = "\r\n"
+	}
+	_, err := e.bw.WriteString(lineEnding)
+	return err
+}
+
+// bomReader is the io.Reader that removes the BOM at the beginning of the input.
+type bomReader struct {
+	r       *bufio.Reader
+	checked bool
+}
+
+func newBOMReader(r io.Reader) *bomReader {
+	return &bomReader{
+		r:       bufio.NewReader(r),
+		checked: false,
+	}
+}
+
+func (b *bomReader) Read(p []byte) (int, error) {
+	b.skipBOM()
+	return b.r.Read(p)
+}
+
+// readLine reads a line including the line break.
+func (b *bomReader) readLine() ([]byte, error) {
+	b.skipBOM()
+
+	var line []byte
+	for {
+		fragment, err := b.r.ReadSlice('\n')
+		line = append(line, fragment...)
+		if err != bufio.ErrBufferFull {
+			return line, err
+		}
+	}
+}
+
+func (b *bomReader) skipBOM() {
+	if b.checked {
+		return
+	}
+	b.checked = true
+	if bom, err := b.r.Peek(len(utf8BOM)); err == nil && string(bom) == utf8BOM {
+		_, _ = b.r.Discard(len(utf8BOM))
+	}
+}
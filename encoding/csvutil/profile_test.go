@@ -0,0 +1,108 @@
+package csvutil_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.nanasi880.dev/x/encoding/csvutil"
+)
+
+func TestEncoder_SetProfile(t *testing.T) {
+
+	type csvData struct {
+		Name  string `csv:"name"`
+		Value int    `csv:"value"`
+	}
+
+	d := []csvData{
+		{Name: "a\nb", Value: 1},
+		{Name: "c\"d", Value: 2},
+	}
+
+	testSuites := []struct {
+		profile csvutil.Profile
+		setup   func(e *csvutil.Encoder)
+		want    string
+	}{
+		{
+			profile: csvutil.ProfileUnix,
+			want:    "name,value\n\"a\nb\",1\n\"c\"\"d\",2\n",
+		},
+		{
+			profile: csvutil.ProfileRFC4180,
+			want:    "name,value\r\n\"a\r\nb\",1\r\n\"c\"\"d\",2\r\n",
+		},
+		{
+			profile: csvutil.ProfileExcel,
+			want:    "\ufeff\"name\",\"value\"\r\n\"a\r\nb\",\"1\"\r\n\"c\"\"d\",\"2\"\r\n",
+		},
+		{
+			// the options set before SetProfile are overwritten
+			profile: csvutil.ProfileUnix,
+			setup: func(e *csvutil.Encoder) {
+				e.QuoteAll = true
+				e.UseHeader = false
+			},
+			want: "name,value\n\"a\nb\",1\n\"c\"\"d\",2\n",
+		},
+	}
+
+	for i, suite := range testSuites {
+		out := new(strings.Builder)
+		enc := csvutil.NewEncoder(out)
+		if suite.setup != nil {
+			suite.setup(enc)
+		}
+		enc.SetProfile(suite.profile)
+		if !enc.UseHeader {
+			t.Fatal(i, "all profiles emit the header")
+		}
+		if err := enc.Encode(d); err != nil {
+			t.Fatal(i, err)
+		}
+		if out.String() != suite.want {
+			t.Fatalf("%d: want: %q got: %q", i, suite.want, out.String())
+		}
+
+		var decoded []csvData
+		if err := csvutil.UnmarshalString(out.String(), &decoded); err != nil {
+			t.Fatal(i, err)
+		}
+		if !reflect.DeepEqual(decoded, d) {
+			t.Fatal(i, decoded)
+		}
+	}
+
+	// the options can be changed after SetProfile
+	out := new(strings.Builder)
+	enc := csvutil.NewEncoder(out)
+	enc.SetProfile(csvutil.ProfileExcel)
+	enc.QuoteAll = false
+	enc.UseHeader = false
+	if err := enc.Encode(d[1:]); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\ufeff\"c\"\"d\",2\r\n"; out.String() != want {
+		t.Fatalf("want: %q got: %q", want, out.String())
+	}
+}
+
+func TestDecoder_Decode_BOM(t *testing.T) {
+
+	type csvData struct {
+		Name  string `csv:"name"`
+		Value int    `csv:"value"`
+	}
+
+	var decoded []csvData
+	if err := csvutil.UnmarshalString("\ufeffname,value\nfoo,1\n\ufeffbar,2\n", &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	// only the BOM at the beginning of the input is removed
+	want := []csvData{{Name: "foo", Value: 1}, {Name: "\ufeffbar", Value: 2}}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Fatal(decoded)
+	}
+}
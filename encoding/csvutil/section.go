@@ -0,0 +1,270 @@
+package csvutil
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+)
+
+// sectionReader is the io.Reader between the input and csv.Reader.
+// It skips the leading lines of the input and the section, and stops at the end of the section,
+// so that csv.Reader reads only the records of the current section.
+// The skipped lines are replaced with the blank lines, which are ignored by csv.Reader, to keep the line numbers.
+type sectionReader struct {
+	d       *Decoder // d is the Decoder which has the options.
+	r       *bomReader
+	mode    sectionMode
+	pending []byte // pending is the bytes passed to csv.Reader.
+	carry   []byte // carry is the header of the next section found at the end of the current section.
+	started bool   // started is whether the first record of the section has been passed to csv.Reader.
+	end     bool   // end is whether the current section ends.
+	eof     bool   // eof is whether the input ends.
+	lines   int    // lines is the number of the lines read from the input, except carry.
+}
+
+// sectionMode is determined on the first read, since the options must be set before decoding.
+type sectionMode int
+
+const (
+	sectionModeUnknown sectionMode = iota
+	sectionModeDirect              // the options are not used, and the input is passed to csv.Reader as it is.
+	sectionModeRecord              // the input is read record by record to apply the options.
+)
+
+func newSectionReader(r *bomReader) *sectionReader {
+	return &sectionReader{
+		d:    nil,
+		r:    r,
+		mode: sectionModeUnknown,
+	}
+}
+
+func (s *sectionReader) Read(p []byte) (int, error) {
+
+	if err := s.init(); err != nil {
+		return 0, err
+	}
+	if s.mode == sectionModeDirect {
+		return s.r.Read(p)
+	}
+
+	for len(s.pending) == 0 {
+		if s.end {
+			return 0, io.EOF
+		}
+		if err := s.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+// init determines the mode on the first call, and skips the leading lines.
+func (s *sectionReader) init() error {
+
+	if s.mode != sectionModeUnknown {
+		return nil
+	}
+
+	s.mode = sectionModeDirect
+	if s.d.SkipLines > 0 || s.d.SkipUntil != nil || s.d.StopAt != nil || s.d.Sections {
+		s.mode = sectionModeRecord
+		return s.skipLines(s.d.SkipLines)
+	}
+	return nil
+}
+
+// skipLines skips n lines at the beginning of the input.
+func (s *sectionReader) skipLines(n int) error {
+	for i := 0; i < n; i++ {
+		line, err := s.r.readLine()
+		if len(line) > 0 {
+			s.lines++
+			s.pending = append(s.pending, '\n')
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// next reads the next record, and passes it to csv.Reader or ends the section.
+func (s *sectionReader) next() error {
+
+	record := s.carry
+	s.carry = nil
+	if record == nil {
+		var err error
+		record, err = s.readRecord()
+		if err != nil {
+			return err
+		}
+		if len(record) == 0 {
+			s.end, s.eof = true, true
+			return nil
+		}
+	}
+	lines := bytes.Count(record, []byte{'\n'})
+
+	blank := len(bytes.TrimRight(record, "\r\n")) == 0
+	comment := s.d.Comment != 0 && bytes.HasPrefix(record, []byte(string(s.d.Comment)))
+
+	switch {
+	case blank || comment:
+		if s.started && blank && s.d.Sections {
+			// the blank line is the end of the section
+			s.end = true
+			s.lines += lines
+			return nil
+		}
+		// csv.Reader ignores the blank lines and the comments
+
+	case !s.started:
+		if s.d.SkipUntil != nil && !s.d.SkipUntil(s.parse(record)) {
+			record = bytes.Repeat([]byte{'\n'}, lines)
+			break
+		}
+		s.started = true
+
+	case s.d.StopAt != nil && s.d.StopAt(s.parse(record)):
+		// the footer is the end of the section
+		s.end = true
+		s.lines += lines
+		return nil
+
+	case s.d.Sections && s.d.SkipUntil != nil && s.d.SkipUntil(s.parse(record)):
+		// the header of the next section is the end of the current section
+		s.end = true
+		s.carry = record
+		return nil
+	}
+
+	s.lines += lines
+	s.pending = record
+	return nil
+}
+
+// readRecord reads the lines of a record. The line breaks in the quoted fields are included in the record.
+// At the end of the input, readRecord returns the empty record.
+func (s *sectionReader) readRecord() ([]byte, error) {
+
+	var (
+		record  []byte
+		inQuote = false
+	)
+	for {
+		line, err := s.r.readLine()
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		comment := s.d.Comment != 0 && len(record) == 0 && bytes.HasPrefix(line, []byte(string(s.d.Comment)))
+		if !comment && bytes.Count(line, []byte{'"'})%2 == 1 {
+			inQuote = !inQuote
+		}
+		record = append(record, line...)
+
+		if err == io.EOF || !inQuote {
+			if err == io.EOF && len(record) > 0 && record[len(record)-1] != '\n' {
+				// the last line without the line break is counted as a line
+				record = append(record, '\n')
+			}
+			return record, nil
+		}
+	}
+}
+
+// parse returns the fields of the record for SkipUntil and StopAt.
+// The record which cannot be parsed is treated as a record of one field.
+func (s *sectionReader) parse(record []byte) []string {
+
+	r := csv.NewReader(bytes.NewReader(record))
+	r.Comma = s.d.Comma
+	r.LazyQuotes = true
+	r.TrimLeadingSpace = s.d.TrimLeadingSpace
+	r.FieldsPerRecord = -1
+
+	fields, err := r.Read()
+	if err != nil {
+		return []string{string(bytes.TrimRight(record, "\r\n"))}
+	}
+	return fields
+}
+
+// nextSection advances to the next section. The rest of the current section is discarded.
+// The second return value is the number of the lines before the next section.
+func (s *sectionReader) nextSection() (int, error) {
+
+	if err := s.init(); err != nil {
+		return 0, err
+	}
+	if s.mode == sectionModeDirect {
+		return 0, io.EOF
+	}
+
+	for !s.end {
+		s.pending = nil
+		if err := s.next(); err != nil {
+			return 0, err
+		}
+	}
+	s.pending = nil
+	if s.eof || (s.carry == nil && s.peekEOF()) {
+		s.eof = true
+		return 0, io.EOF
+	}
+
+	s.end = false
+	s.started = false
+	return s.lines, nil
+}
+
+// peekEOF returns whether the input ends, skipping the blank lines.
+func (s *sectionReader) peekEOF() bool {
+	for {
+		b, err := s.r.r.Peek(1)
+		if err != nil || len(b) == 0 {
+			return true
+		}
+		if b[0] != '\n' && b[0] != '\r' {
+			return false
+		}
+		_, _ = s.r.r.Discard(1)
+		if b[0] == '\n' {
+			s.lines++
+		}
+	}
+}
+
+// NextSection advances the Decoder to the next section of the input, and the header is read again on the next decoding.
+// The sections are separated by the blank lines if Sections is true, by the records matched by StopAt,
+// and by the records matched by SkipUntil if Sections is true.
+// The rest of the current section is discarded. If there are no more sections, NextSection returns io.EOF.
+func (d *Decoder) NextSection() error {
+
+	if d.sections == nil {
+		return io.EOF
+	}
+
+	lines, err := d.sections.nextSection()
+	if err != nil {
+		return err
+	}
+
+	d.r = csv.NewReader(d.sections)
+	d.setupReader()
+	d.lineOffset = lines
+	d.header = nil
+	d.fieldIndex = nil
+	d.fieldIndexType = nil
+	d.plan = nil
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package csvutil_test
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.nanasi880.dev/x/encoding/csvutil"
+)
+
+func TestDecoder_SkipLines(t *testing.T) {
+
+	type csvData struct {
+		ID   int    `csv:"id"`
+		Name string `csv:"name"`
+	}
+
+	const input = "Monthly Report\n" +
+		"Generated at \"2021-01-01\n" +
+		"id,name\n" +
+		"1,foo\n" +
+		"x,bar\n"
+
+	dec := csvutil.NewDecoder(strings.NewReader(input))
+	dec.SkipLines = 2
+
+	var decoded []csvData
+	err := dec.Decode(&decoded)
+
+	// the line numbers include the skipped lines
+	var decodeErr *csvutil.DecodeError
+	if !errors.As(err, &decodeErr) || decodeErr.Line != 5 {
+		t.Fatal(err)
+	}
+}
+
+func TestDecoder_SkipUntil_StopAt(t *testing.T) {
+
+	type csvData struct {
+		ID   int    `csv:"id"`
+		Name string `csv:"name"`
+	}
+
+	const input = "Report,,\n" +
+		"\n" +
+		"id,name\n" +
+		"1,foo\n" +
+		"2,\"bar\n" +
+		"baz\"\n" +
+		"Total,2\n" +
+		"Signature\n"
+
+	dec := csvutil.NewDecoder(strings.NewReader(input))
+	dec.SkipUntil = func(record []string) bool { return record[0] == "id" }
+	dec.StopAt = func(record []string) bool { return record[0] == "Total" }
+
+	var decoded []csvData
+	if err := dec.Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []csvData{{ID: 1, Name: "foo"}, {ID: 2, Name: "bar\nbaz"}}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Fatal(decoded)
+	}
+}
+
+func TestDecoder_NextSection(t *testing.T) {
+
+	type user struct {
+		ID   int    `csv:"id"`
+		Name string `csv:"name"`
+	}
+	type order struct {
+		OrderID int     `csv:"order_id"`
+		UserID  int     `csv:"user_id"`
+		Amount  float64 `csv:"amount"`
+	}
+	type item struct {
+		SKU string `csv:"sku"`
+	}
+
+	const input = "Users\n" +
+		"id,name\n" +
+		"1,foo\n" +
+		"2,bar\n" +
+		"\n" +
+		"\n" +
+		"Orders\n" +
+		"order_id,user_id,amount\n" +
+		"10,1,1.5\n" +
+		"11,2,x\n" +
+		"sku\n" +
+		"A-1\n" +
+		"\n"
+
+	dec := csvutil.NewDecoder(strings.NewReader(input))
+	dec.Sections = true
+	dec.SkipUntil = func(record []string) bool {
+		switch record[0] {
+		case "id", "order_id", "sku":
+			return true
+		default:
+			return false
+		}
+	}
+
+	var users []user
+	if err := dec.Decode(&users); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(users, []user{{ID: 1, Name: "foo"}, {ID: 2, Name: "bar"}}) {
+		t.Fatal(users)
+	}
+
+	if err := dec.NextSection(); err != nil {
+		t.Fatal(err)
+	}
+	var first order
+	if err := dec.DecodeNext(&first); err != nil {
+		t.Fatal(err)
+	}
+	if first != (order{OrderID: 10, UserID: 1, Amount: 1.5}) {
+		t.Fatal(first)
+	}
+	var decodeErr *csvutil.DecodeError
+	if err := dec.DecodeNext(&first); !errors.As(err, &decodeErr) || decodeErr.Line != 10 || decodeErr.Header != "amount" {
+		t.Fatal(err)
+	}
+
+	// the section is delimited by the header of the next section
+	if err := dec.NextSection(); err != nil {
+		t.Fatal(err)
+	}
+	var items []item
+	if err := dec.Decode(&items); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(items, []item{{SKU: "A-1"}}) {
+		t.Fatal(items)
+	}
+
+	if err := dec.NextSection(); err != io.EOF {
+		t.Fatal(err)
+	}
+}
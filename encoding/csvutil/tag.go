@@ -0,0 +1,155 @@
+package csvutil
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// tagOptions is the options of the csv struct tag.
+// e.g. `csv:"name,required"` has the option "required".
+type tagOptions map[string]string
+
+// csvTagOptions is the keys of the options of the csv struct tag.
+var csvTagOptions = []string{
+	"index", "required",
+	"layout", "tz", "format", "true", "false", "default", "nil", "empty",
+	"join", "expand", "keys",
+}
+
+// parseTag returns the name and the options of the struct tag.
+// The options are a comma separated list of `key` or `key=value`.
+// The value which contains commas is quoted by single quotes, e.g. `layout='Jan 2, 2006'` and `join=','`,
+// and the single quote in the quoted value is written as two single quotes. The empty option is an error.
+func parseTag(tag string) (string, tagOptions, error) {
+
+	name, rest := tag, ""
+	if i := strings.IndexByte(tag, ','); i >= 0 {
+		name, rest = tag[:i], tag[i+1:]
+	}
+
+	if strings.HasSuffix(rest, ",") {
+		// e.g. `join=,` must be written as `join=','`
+		return "", nil, fmt.Errorf("empty option in %q", tag)
+	}
+
+	options := make(tagOptions)
+	for rest != "" {
+		i := strings.IndexAny(rest, ",=")
+		if i < 0 {
+			options[rest] = ""
+			break
+		}
+
+		key := rest[:i]
+		if key == "" {
+			return "", nil, fmt.Errorf("empty option in %q", tag)
+		}
+		if rest[i] == ',' {
+			options[key] = ""
+			rest = rest[i+1:]
+			continue
+		}
+
+		value, remaining, err := parseTagValue(rest[i+1:])
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid %s option: %w", key, err)
+		}
+		options[key] = value
+		rest = remaining
+	}
+
+	return name, options, nil
+}
+
+// parseTagValue returns the value of the option at the beginning of s, and the rest of the options after the comma.
+func parseTagValue(s string) (string, string, error) {
+
+	if !strings.HasPrefix(s, "'") {
+		if i := strings.IndexByte(s, ','); i >= 0 {
+			return s[:i], s[i+1:], nil
+		}
+		return s, "", nil
+	}
+
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		if s[i] != '\'' {
+			sb.WriteByte(s[i])
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '\'' {
+			// escaped single quote
+			sb.WriteByte('\'')
+			i++
+			continue
+		}
+
+		switch rest := s[i+1:]; {
+		case rest == "":
+			return sb.String(), "", nil
+		case rest[0] == ',':
+			return sb.String(), rest[1:], nil
+		default:
+			return "", "", fmt.Errorf("unexpected %q after the quoted value", rest)
+		}
+	}
+
+	return "", "", fmt.Errorf("unterminated quoted value: %s", s)
+}
+
+// lookupTag returns the name and the options of the csv struct tag of the field.
+// If the name of the tag is empty, the name of the field is used.
+// The third return value is false if the field does not have the csv tag.
+// The unknown option is an error.
+func lookupTag(sf reflect.StructField) (string, tagOptions, bool, error) {
+
+	tag, ok := sf.Tag.Lookup("csv")
+	if !ok {
+		return sf.Name, tagOptions{}, false, nil
+	}
+
+	name, options, err := parseTag(tag)
+	if err != nil {
+		return "", nil, true, err
+	}
+	if err := options.validate(csvTagOptions); err != nil {
+		return "", nil, true, err
+	}
+	if name == "" {
+		name = sf.Name
+	}
+	return name, options, true, nil
+}
+
+// has returns whether the option is specified.
+func (o tagOptions) has(key string) bool {
+	_, ok := o[key]
+	return ok
+}
+
+// validate returns an error if the options contain the key which is not in keys.
+func (o tagOptions) validate(keys []string) error {
+
+	var unknown []string
+	for key := range o {
+		if !containsString(keys, key) {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown option: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
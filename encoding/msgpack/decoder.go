@@ -36,6 +36,7 @@ type Decoder struct {
 	structKeyType        StructKeyType
 	structTagName        string
 	arrayLengthTolerance ArrayLengthTolerance
+	mergeMode            MergeMode
 	timeZone             *time.Location
 }
 
@@ -48,6 +49,7 @@ func NewDecoder(r io.Reader) *Decoder {
 		structKeyType:        StructKeyTypeInt,
 		structTagName:        "msgpack",
 		arrayLengthTolerance: ArrayLengthToleranceLessThanOrEqual,
+		mergeMode:            MergeModeDefault,
 		timeZone:             nil,
 	}
 }
@@ -64,6 +66,7 @@ func NewDecoderBytes(in []byte) *Decoder {
 		structKeyType:        StructKeyTypeInt,
 		structTagName:        "msgpack",
 		arrayLengthTolerance: ArrayLengthToleranceLessThanOrEqual,
+		mergeMode:            MergeModeDefault,
 		timeZone:             nil,
 	}
 }
@@ -77,6 +80,7 @@ func (d *Decoder) Reset(r io.Reader) {
 		structKeyType:        StructKeyTypeInt,
 		structTagName:        "msgpack",
 		arrayLengthTolerance: ArrayLengthToleranceLessThanOrEqual,
+		mergeMode:            MergeModeDefault,
 		timeZone:             nil,
 	}
 	if d.reader == nil {
@@ -97,6 +101,7 @@ func (d *Decoder) ResetBytes(in []byte) {
 		structKeyType:        StructKeyTypeInt,
 		structTagName:        "msgpack",
 		arrayLengthTolerance: ArrayLengthToleranceLessThanOrEqual,
+		mergeMode:            MergeModeDefault,
 		timeZone:             nil,
 	}
 	if d.reader != nil {
@@ -116,6 +121,13 @@ func (d *Decoder) SetArrayLengthTolerance(tolerance ArrayLengthTolerance) *Decod
 	return d
 }
 
+// SetMergeMode is set MergeMode to Decoder.
+// The decoder will decode into existing maps, slices and pointers according to this mode. The default is MergeModeDefault.
+func (d *Decoder) SetMergeMode(mode MergeMode) *Decoder {
+	d.mergeMode = mode
+	return d
+}
+
 // SetTimeZone is set time zone to Decoder.
 // The decoder will set this time zone to the time when decoding. If loc is nil, use the UTC time.
 func (d *Decoder) SetTimeZone(loc *time.Location) *Decoder {
@@ -56,7 +56,9 @@ func (d *Decoder) decodeValue(rv reflect.Value) error {
 
 func (d *Decoder) decodeUnmarshaler(rv reflect.Value) error {
 	for {
-		if reflectutil.IsNilable(rv) && rv.IsNil() {
+		if rv.Kind() == reflect.Ptr {
+			d.allocatePointer(rv)
+		} else if reflectutil.IsNilable(rv) && rv.IsNil() {
 			reflectutil.AllocateTo(rv)
 		}
 		if rv.Type().Implements(unmarshalerType) {
@@ -439,9 +441,7 @@ func (d *Decoder) decodeArrayToStruct(rv reflect.Value, format FormatName, b byt
 
 	// allocate
 	for rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			reflectutil.AllocateTo(rv)
-		}
+		d.allocatePointer(rv)
 		rv = rv.Elem()
 	}
 
@@ -488,9 +488,7 @@ func (d *Decoder) decodeArrayToArray(rv reflect.Value, format FormatName, b byte
 
 	// allocate
 	for rv.Kind() == reflect.Ptr {
-		if reflectutil.IsNilable(rv) && rv.IsNil() {
-			reflectutil.AllocateTo(rv)
-		}
+		d.allocatePointer(rv)
 		rv = rv.Elem()
 	}
 
@@ -501,7 +499,10 @@ func (d *Decoder) decodeArrayToArray(rv reflect.Value, format FormatName, b byte
 
 	// grow capacity
 	if rv.Kind() == reflect.Slice {
-		if rv.Len() < length {
+		if !d.mergeMode.Has(MergeModeSlice) {
+			// always allocate a new slice
+			rv.Set(reflect.MakeSlice(rv.Type(), length, length))
+		} else if rv.Len() < length {
 			if rv.Cap() >= length {
 				// fast pass
 				rv.SetLen(length)
@@ -509,6 +510,9 @@ func (d *Decoder) decodeArrayToArray(rv reflect.Value, format FormatName, b byte
 				// slow pass
 				rv.Set(reflect.MakeSlice(rv.Type(), length, length))
 			}
+		} else if rv.Len() > length {
+			// reuse the head of the slice
+			rv.SetLen(length)
 		}
 	}
 	if reflectutil.IsNilable(rv) && rv.IsNil() {
@@ -596,9 +600,7 @@ func (d *Decoder) decodeMapToStruct(rv reflect.Value, format FormatName, b byte)
 
 	// allocate
 	for rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			reflectutil.AllocateTo(rv)
-		}
+		d.allocatePointer(rv)
 		rv = rv.Elem()
 	}
 
@@ -641,23 +643,20 @@ func (d *Decoder) decodeMapToMap(rv reflect.Value, format FormatName, b byte) er
 
 	// allocate
 	for rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			reflectutil.AllocateTo(rv)
-		}
+		d.allocatePointer(rv)
 		rv = rv.Elem()
 	}
 	if rv.Type() == interfaceType {
 		rv.Set(reflect.MakeMap(interfaceMapType))
 		rv = rv.Elem()
+	} else if rv.IsNil() || !d.mergeMode.Has(MergeModeMap) {
+		rv.Set(reflect.MakeMapWithSize(rv.Type(), length))
 	}
 
 	var (
 		keyType   = rv.Type().Key()
 		valueType = rv.Type().Elem()
 	)
-	if rv.IsNil() {
-		rv.Set(reflect.MakeMap(reflect.MapOf(keyType, valueType)))
-	}
 	for i := 0; i < length; i++ {
 		var (
 			key   = reflect.New(keyType)
@@ -666,6 +665,11 @@ func (d *Decoder) decodeMapToMap(rv reflect.Value, format FormatName, b byte) er
 		if err := d.decodeValue(key); err != nil {
 			return err
 		}
+		if d.mergeMode.Has(MergeModeMapValue) {
+			if current := rv.MapIndex(key.Elem()); current.IsValid() {
+				value.Elem().Set(current)
+			}
+		}
 		if err := d.decodeValue(value); err != nil {
 			return err
 		}
@@ -857,9 +861,7 @@ func (d *Decoder) validateMap(rv reflect.Value) bool {
 
 func (d *Decoder) setInt(rv reflect.Value, v int64) error {
 	for rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			reflectutil.AllocateTo(rv)
-		}
+		d.allocatePointer(rv)
 		rv = rv.Elem()
 	}
 	if reflectutil.IsSignedInt(rv.Kind()) {
@@ -883,9 +885,7 @@ func (d *Decoder) setInt(rv reflect.Value, v int64) error {
 
 func (d *Decoder) setUint(rv reflect.Value, v uint64) error {
 	for rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			reflectutil.AllocateTo(rv)
-		}
+		d.allocatePointer(rv)
 		rv = rv.Elem()
 	}
 	if reflectutil.IsUnsignedInt(rv.Kind()) {
@@ -909,9 +909,7 @@ func (d *Decoder) setUint(rv reflect.Value, v uint64) error {
 
 func (d *Decoder) setFloat32(rv reflect.Value, v float32) error {
 	for rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			reflectutil.AllocateTo(rv)
-		}
+		d.allocatePointer(rv)
 		rv = rv.Elem()
 	}
 	if reflectutil.IsFloat(rv.Kind()) {
@@ -935,9 +933,7 @@ func (d *Decoder) setFloat32(rv reflect.Value, v float32) error {
 
 func (d *Decoder) setFloat64(rv reflect.Value, v float64) error {
 	for rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			reflectutil.AllocateTo(rv)
-		}
+		d.allocatePointer(rv)
 		rv = rv.Elem()
 	}
 	if reflectutil.IsFloat(rv.Kind()) {
@@ -961,9 +957,7 @@ func (d *Decoder) setFloat64(rv reflect.Value, v float64) error {
 
 func (d *Decoder) setString(rv reflect.Value, v []byte) error {
 	for rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			reflectutil.AllocateTo(rv)
-		}
+		d.allocatePointer(rv)
 		rv = rv.Elem()
 	}
 	if rv.Kind() == reflect.String {
@@ -986,9 +980,7 @@ func (d *Decoder) setString(rv reflect.Value, v []byte) error {
 
 func (d *Decoder) setBool(rv reflect.Value, v bool) error {
 	for rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			reflectutil.AllocateTo(rv)
-		}
+		d.allocatePointer(rv)
 		rv = rv.Elem()
 	}
 	if rv.Kind() == reflect.Bool {
@@ -1004,9 +996,7 @@ func (d *Decoder) setBool(rv reflect.Value, v bool) error {
 
 func (d *Decoder) setBin(rv reflect.Value, v []byte) error {
 	for rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			reflectutil.AllocateTo(rv)
-		}
+		d.allocatePointer(rv)
 		rv = rv.Elem()
 	}
 	if rv.Type() == byteSliceType {
@@ -1029,9 +1019,7 @@ func (d *Decoder) setBin(rv reflect.Value, v []byte) error {
 
 func (d *Decoder) setTime(rv reflect.Value, v time.Time) error {
 	for rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			reflectutil.AllocateTo(rv)
-		}
+		d.allocatePointer(rv)
 		rv = rv.Elem()
 	}
 	if rv.Type() == timeType {
@@ -1045,6 +1033,19 @@ func (d *Decoder) setTime(rv reflect.Value, v time.Time) error {
 	return fmt.Errorf("internal: unsupported kind: %s", rv.Kind())
 }
 
+// allocatePointer is allocate the value pointed by rv if rv is nil, or if the decoder does not reuse pointers.
+// rv must be a pointer.
+func (d *Decoder) allocatePointer(rv reflect.Value) {
+	if rv.IsNil() {
+		reflectutil.AllocateTo(rv)
+		return
+	}
+	// the pointer passed to Decode cannot be replaced
+	if rv.CanSet() && !d.mergeMode.Has(MergeModePointer) {
+		reflectutil.AllocateTo(rv)
+	}
+}
+
 func (d *Decoder) lookupStringIndex(indexes map[string]int) (int, bool, error) {
 	format, b, err := d.readFormat()
 	if err != nil {
@@ -577,6 +577,167 @@ func TestDecoder_CustomStructTag(t *testing.T) {
 	}
 }
 
+func TestDecoder_SetMergeMode(t *testing.T) {
+	type Inner struct {
+		A int
+		B string
+	}
+	type Data struct {
+		Map   map[string]Inner
+		Slice []int
+		Ptr   *Inner
+	}
+
+	type PartialA struct {
+		A int
+	}
+	type PartialB struct {
+		B string
+	}
+	partial := struct {
+		Map   map[string]PartialA
+		Slice []int
+		Ptr   PartialB
+	}{
+		Map:   map[string]PartialA{"x": {A: 10}},
+		Slice: []int{7, 8},
+		Ptr:   PartialB{B: "updated"},
+	}
+	buf, err := msgpack.MarshalStringKey(partial)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newState := func() (Data, []int, *Inner) {
+		slice := make([]int, 0, 8)
+		ptr := &Inner{A: 1, B: "original"}
+		return Data{
+			Map:   map[string]Inner{"x": {A: 1, B: "x"}, "y": {A: 2, B: "y"}},
+			Slice: slice,
+			Ptr:   ptr,
+		}, slice, ptr
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		state, slice, ptr := newState()
+		dec := msgpack.NewDecoderBytes(buf).SetStructKeyType(msgpack.StructKeyTypeString)
+		if err := dec.Decode(&state); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(state.Map, map[string]Inner{"x": {A: 10}, "y": {A: 2, B: "y"}}) {
+			t.Fatal(state.Map)
+		}
+		if !reflect.DeepEqual(state.Slice, []int{7, 8}) || &state.Slice[0] != &slice[:1][0] {
+			t.Fatal(state.Slice)
+		}
+		if state.Ptr != ptr || *state.Ptr != (Inner{A: 1, B: "updated"}) {
+			t.Fatal(state.Ptr)
+		}
+	})
+	t.Run("MergeModeNone", func(t *testing.T) {
+		state, slice, ptr := newState()
+		dec := msgpack.NewDecoderBytes(buf).
+			SetStructKeyType(msgpack.StructKeyTypeString).
+			SetMergeMode(msgpack.MergeModeNone)
+		if err := dec.Decode(&state); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(state.Map, map[string]Inner{"x": {A: 10}}) {
+			t.Fatal(state.Map)
+		}
+		if !reflect.DeepEqual(state.Slice, []int{7, 8}) || &state.Slice[0] == &slice[:1][0] {
+			t.Fatal(state.Slice)
+		}
+		if state.Ptr == ptr || *state.Ptr != (Inner{B: "updated"}) {
+			t.Fatal(state.Ptr)
+		}
+	})
+	t.Run("MergeModeAll", func(t *testing.T) {
+		state, slice, ptr := newState()
+		dec := msgpack.NewDecoderBytes(buf).
+			SetStructKeyType(msgpack.StructKeyTypeString).
+			SetMergeMode(msgpack.MergeModeAll)
+		if err := dec.Decode(&state); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(state.Map, map[string]Inner{"x": {A: 10, B: "x"}, "y": {A: 2, B: "y"}}) {
+			t.Fatal(state.Map)
+		}
+		if !reflect.DeepEqual(state.Slice, []int{7, 8}) || &state.Slice[0] != &slice[:1][0] {
+			t.Fatal(state.Slice)
+		}
+		if state.Ptr != ptr || *state.Ptr != (Inner{A: 1, B: "updated"}) {
+			t.Fatal(state.Ptr)
+		}
+	})
+}
+
+func TestDecoder_Decode_PrePopulated(t *testing.T) {
+	type D struct {
+		M map[string]int
+		S []int
+		P *int
+	}
+
+	buf, err := msgpack.MarshalStringKey(struct {
+		M map[string]int
+		S []int
+		P int
+	}{
+		M: map[string]int{"x": 1},
+		S: []int{1, 2},
+		P: 3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		p     = new(int)
+		slice = make([]int, 0, 10)
+		d     = D{M: map[string]int{"y": 2}, S: slice, P: p}
+	)
+	dec := msgpack.NewDecoderBytes(buf).SetStructKeyType(msgpack.StructKeyTypeString)
+	if err := dec.Decode(&d); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(d.M, map[string]int{"x": 1, "y": 2}) {
+		t.Fatal(d.M)
+	}
+	if !reflect.DeepEqual(d.S, []int{1, 2}) || cap(d.S) != 10 || &d.S[0] != &slice[:1][0] {
+		t.Fatal(d.S, cap(d.S))
+	}
+	if d.P != p || *p != 3 {
+		t.Fatal(d.P)
+	}
+}
+
+func TestDecoder_Decode_ShorterSlice(t *testing.T) {
+
+	buf, err := msgpack.Marshal([]int{7, 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, mode := range []msgpack.MergeMode{msgpack.MergeModeDefault, msgpack.MergeModeAll} {
+		slice := []int{1, 2, 3}
+		dec := msgpack.NewDecoderBytes(buf).SetMergeMode(mode)
+		if err := dec.Decode(&slice); err != nil {
+			t.Fatal(mode, err)
+		}
+		if !reflect.DeepEqual(slice, []int{7, 8}) || cap(slice) != 3 {
+			t.Fatal(mode, slice, cap(slice))
+		}
+	}
+
+	// the length of the go array is still checked by ArrayLengthTolerance
+	var array [3]int
+	if err := msgpack.NewDecoderBytes(buf).Decode(&array); err == nil {
+		t.Fatal(array)
+	}
+}
+
 func ExampleDecoder_Decode_streaming() {
 
 	examples := make([]int, 0)
@@ -0,0 +1,40 @@
+package msgpack
+
+// MergeMode is determines how to decode into existing values.
+// MergeMode is a bit set, so the modes can be combined.
+type MergeMode byte
+
+const (
+	// MergeModeMap is keep the existing entries of the map and add the decoded entries to it.
+	// If not set, the decoder allocates a new map.
+	MergeModeMap MergeMode = 1 << iota
+
+	// MergeModeSlice is reuse the backing array of the slice if the capacity is large enough, and decode each element onto the existing element.
+	// If not set, the decoder allocates a new slice.
+	MergeModeSlice
+
+	// MergeModePointer is reuse the non-nil pointer and decode onto the pointed value.
+	// If not set, the decoder allocates a new value for every pointer.
+	MergeModePointer
+
+	// MergeModeMapValue is decode each value of the map onto the existing value of the same key, instead of the zero value.
+	// It is effective with MergeModeMap.
+	MergeModeMapValue
+)
+
+const (
+	// MergeModeNone is always allocate a new value for maps, slices and pointers.
+	MergeModeNone MergeMode = 0
+
+	// MergeModeDefault is the default mode of Decoder.
+	// The non-nil pointers and the backing arrays of the slices are reused, and the decoded entries are added to the existing map.
+	MergeModeDefault = MergeModeMap | MergeModeSlice | MergeModePointer
+
+	// MergeModeAll is reuse maps, slices and pointers as much as possible.
+	MergeModeAll = MergeModeDefault | MergeModeMapValue
+)
+
+// Has returns whether m contains all modes of mode.
+func (m MergeMode) Has(mode MergeMode) bool {
+	return m&mode == mode
+}
@@ -0,0 +1,438 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ParseDotenv parses r in the dotenv format, and returns the environment variables in the form "key=value".
+//
+// The format is the lines of KEY=VALUE:
+//
+//	# comment
+//	export KEY=value           the export prefix is ignored.
+//	KEY=value # comment        the unquoted value is trimmed, and the comment starts with " #".
+//	KEY='literal ${VAR}'       the single-quoted value is not expanded.
+//	KEY="line1\nline2"         the double-quoted value supports the escapes \n, \r, \t, \", \\ and \$.
+//	KEY="multi
+//	line"                      the quoted value can span multiple lines.
+//	KEY=${VAR} $VAR            the variable reference is expanded.
+//	KEY=${VAR:-default}        the default is used if VAR is unset or empty.
+//	KEY=${VAR-default}         the default is used if VAR is unset.
+//
+// The variable reference is expanded by the variables defined before in r, and then by lookup.
+// If lookup is nil, os.LookupEnv is used. The undefined variable is expanded to the empty string.
+func ParseDotenv(r io.Reader, lookup func(name string) (string, bool)) ([]string, error) {
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+
+	p := &dotenvParser{
+		src:    string(b),
+		line:   1,
+		lookup: lookup,
+		values: make(map[string]string),
+	}
+	return p.parse()
+}
+
+// LoadDotenv reads the dotenv files, and returns the environment variables layered in the order of the files.
+// The variable in the later file overrides the variable in the former file.
+// The variable reference is expanded by the process environment, and then by the variables defined before.
+func LoadDotenv(filenames ...string) ([]string, error) {
+
+	var environments []string
+	for _, filename := range filenames {
+
+		layered := environments
+		lookup := func(name string) (string, bool) {
+			if v, ok := os.LookupEnv(name); ok {
+				return v, true
+			}
+			return lookupEnvironment(layered, name)
+		}
+
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := ParseDotenv(f, lookup)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+
+		environments = Merge(environments, parsed)
+	}
+
+	return environments, nil
+}
+
+// Environ returns the process environment layered over the dotenv files.
+// The precedence is the process environment, and then the files in the reverse order, i.e. the later file overrides the former.
+// The result is passed to VariableSet.Parse.
+func Environ(filenames ...string) ([]string, error) {
+
+	environments, err := LoadDotenv(filenames...)
+	if err != nil {
+		return nil, err
+	}
+	return Merge(environments, os.Environ()), nil
+}
+
+// Merge merges the environments in the form "key=value". The variable in the later environment overrides the former.
+// The variables are ordered by the first appearance.
+func Merge(environments ...[]string) []string {
+
+	var (
+		merged []string
+		index  = make(map[string]int)
+	)
+	for _, environment := range environments {
+		for _, pair := range environment {
+			key := pair
+			if i := strings.IndexByte(pair, '='); i >= 0 {
+				key = pair[:i]
+			}
+			if i, ok := index[key]; ok {
+				merged[i] = pair
+				continue
+			}
+			index[key] = len(merged)
+			merged = append(merged, pair)
+		}
+	}
+	return merged
+}
+
+// lookupEnvironment returns the last value of the variable in the environment.
+func lookupEnvironment(environment []string, name string) (string, bool) {
+	for i := len(environment) - 1; i >= 0; i-- {
+		pair := environment[i]
+		if strings.HasPrefix(pair, name) && len(pair) > len(name) && pair[len(name)] == '=' {
+			return pair[len(name)+1:], true
+		}
+	}
+	return "", false
+}
+
+// dotenvParser is the parser of the dotenv format.
+type dotenvParser struct {
+	src    string
+	pos    int
+	line   int
+	lookup func(name string) (string, bool)
+	values map[string]string // values is the variables defined before.
+	result []string
+}
+
+func (p *dotenvParser) parse() ([]string, error) {
+
+	for {
+		p.skipSpaces()
+		if p.eof() {
+			return p.result, nil
+		}
+
+		switch p.peek() {
+		case '\n':
+			p.advance()
+			continue
+		case '#':
+			p.skipLine()
+			continue
+		}
+
+		line := p.line
+		if err := p.parseLine(); err != nil {
+			return nil, fmt.Errorf("dotenv: line %d: %w", line, err)
+		}
+	}
+}
+
+func (p *dotenvParser) parseLine() error {
+
+	key := p.parseKey()
+	if key == "export" {
+		p.skipSpaces()
+		if !p.eof() && p.peek() != '=' {
+			key = p.parseKey()
+		}
+	}
+	if key == "" {
+		return fmt.Errorf("invalid variable name")
+	}
+
+	p.skipSpaces()
+	if p.eof() || p.peek() != '=' {
+		return fmt.Errorf("%s: `=` is expected", key)
+	}
+	p.advance()
+	p.skipSpaces()
+
+	var (
+		value string
+		err   error
+	)
+	switch {
+	case p.eof():
+	case p.peek() == '\'':
+		value, err = p.parseSingleQuoted()
+	case p.peek() == '"':
+		value, err = p.parseDoubleQuoted()
+	default:
+		value, err = p.parseUnquoted()
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	p.values[key] = value
+	p.result = append(p.result, key+"="+value)
+	return nil
+}
+
+// parseKey parses the variable name, which consists of letters, digits and '_', and does not start with a digit.
+func (p *dotenvParser) parseKey() string {
+	start := p.pos
+	for !p.eof() {
+		c := p.peek()
+		if !isNameStart(c) && !(c >= '0' && c <= '9' && p.pos > start) {
+			break
+		}
+		p.advance()
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *dotenvParser) parseSingleQuoted() (string, error) {
+
+	p.advance()
+	start := p.pos
+	for !p.eof() && p.peek() != '\'' {
+		p.advance()
+	}
+	if p.eof() {
+		return "", fmt.Errorf("unterminated single-quoted value")
+	}
+	value := p.src[start:p.pos]
+	p.advance()
+
+	return value, p.endOfValue()
+}
+
+func (p *dotenvParser) parseDoubleQuoted() (string, error) {
+
+	p.advance()
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return "", fmt.Errorf("unterminated double-quoted value")
+		}
+
+		c := p.peek()
+		switch c {
+		case '"':
+			p.advance()
+			return sb.String(), p.endOfValue()
+
+		case '\\':
+			p.advance()
+			if p.eof() {
+				continue
+			}
+			switch e := p.peek(); e {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case '"', '\\', '$':
+				sb.WriteByte(e)
+			default:
+				sb.WriteByte('\\')
+				sb.WriteByte(e)
+			}
+			p.advance()
+
+		case '$':
+			expanded, err := p.parseReference()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(expanded)
+
+		default:
+			sb.WriteByte(c)
+			p.advance()
+		}
+	}
+}
+
+func (p *dotenvParser) parseUnquoted() (string, error) {
+
+	var sb strings.Builder
+	for !p.eof() {
+		c := p.peek()
+		if c == '\n' {
+			break
+		}
+		if c == '#' && p.pos > 0 && (p.src[p.pos-1] == ' ' || p.src[p.pos-1] == '\t') {
+			p.skipLine()
+			break
+		}
+
+		if c == '$' {
+			expanded, err := p.parseReference()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(expanded)
+			continue
+		}
+
+		sb.WriteByte(c)
+		p.advance()
+	}
+
+	return strings.TrimRight(sb.String(), " \t\r"), nil
+}
+
+// parseReference parses the variable reference at '$', and returns the expanded value.
+func (p *dotenvParser) parseReference() (string, error) {
+
+	p.advance()
+	if p.eof() || (p.peek() != '{' && !isNameStart(p.peek())) {
+		return "$", nil
+	}
+
+	if p.peek() != '{' {
+		value, _ := p.resolve(p.parseKey())
+		return value, nil
+	}
+
+	p.advance()
+	name := p.parseKey()
+	if name == "" {
+		return "", fmt.Errorf("invalid variable reference")
+	}
+
+	switch {
+	case strings.HasPrefix(p.src[p.pos:], "}"):
+		p.advance()
+		value, _ := p.resolve(name)
+		return value, nil
+
+	case strings.HasPrefix(p.src[p.pos:], ":-"), strings.HasPrefix(p.src[p.pos:], "-"):
+		emptyIsUnset := p.peek() == ':'
+		if emptyIsUnset {
+			p.advance()
+		}
+		p.advance()
+
+		def, err := p.parseDefault()
+		if err != nil {
+			return "", err
+		}
+
+		value, ok := p.resolve(name)
+		if !ok || (emptyIsUnset && value == "") {
+			return def, nil
+		}
+		return value, nil
+
+	default:
+		return "", fmt.Errorf("unterminated variable reference ${%s", name)
+	}
+}
+
+// parseDefault parses the default value of the variable reference until '}'. The default value is expanded.
+func (p *dotenvParser) parseDefault() (string, error) {
+
+	var sb strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", fmt.Errorf("unterminated variable reference")
+		}
+
+		c := p.peek()
+		switch c {
+		case '}':
+			p.advance()
+			return sb.String(), nil
+		case '$':
+			expanded, err := p.parseReference()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(expanded)
+		default:
+			sb.WriteByte(c)
+			p.advance()
+		}
+	}
+}
+
+// resolve returns the value of the variable defined before, or the value of lookup.
+func (p *dotenvParser) resolve(name string) (string, bool) {
+	if value, ok := p.values[name]; ok {
+		return value, true
+	}
+	return p.lookup(name)
+}
+
+// endOfValue skips the spaces and the comment after the quoted value.
+func (p *dotenvParser) endOfValue() error {
+
+	p.skipSpaces()
+	switch {
+	case p.eof():
+	case p.peek() == '\n':
+	case p.peek() == '\r':
+		p.skipLine()
+	case p.peek() == '#':
+		p.skipLine()
+	default:
+		return fmt.Errorf("unexpected character `%c` after the quoted value", p.peek())
+	}
+	return nil
+}
+
+func (p *dotenvParser) skipSpaces() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t' || p.peek() == '\r') {
+		p.advance()
+	}
+}
+
+func (p *dotenvParser) skipLine() {
+	for !p.eof() && p.peek() != '\n' {
+		p.advance()
+	}
+}
+
+func (p *dotenvParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *dotenvParser) peek() byte {
+	return p.src[p.pos]
+}
+
+func (p *dotenvParser) advance() {
+	if p.src[p.pos] == '\n' {
+		p.line++
+	}
+	p.pos++
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
@@ -0,0 +1,135 @@
+package env_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.nanasi880.dev/x/os/env"
+)
+
+func TestParseDotenv(t *testing.T) {
+
+	const src = `# comment
+export HOST=localhost
+PORT = 8080 # inline comment
+EMPTY=
+HASH=a#b
+SINGLE='literal ${HOST} \n'
+DOUBLE="tab\tquote\" dollar\$ ${HOST}"
+MULTI="line1
+line2"
+ADDR=${HOST}:$PORT
+DEFAULT=${UNSET:-fallback}
+EMPTY_DEFAULT=${EMPTY:-empty}
+UNSET_DEFAULT=${EMPTY-unset}
+NESTED=${UNSET:-${HOST}}
+OUTER=${OUTER_VAR}
+CRLF=value` + "\r\n"
+
+	lookup := func(name string) (string, bool) {
+		if name == "OUTER_VAR" {
+			return "outer", true
+		}
+		return "", false
+	}
+
+	parsed, err := env.ParseDotenv(strings.NewReader(src), lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"HOST=localhost",
+		"PORT=8080",
+		"EMPTY=",
+		"HASH=a#b",
+		"SINGLE=literal ${HOST} \\n",
+		"DOUBLE=tab\tquote\" dollar$ localhost",
+		"MULTI=line1\nline2",
+		"ADDR=localhost:8080",
+		"DEFAULT=fallback",
+		"EMPTY_DEFAULT=empty",
+		"UNSET_DEFAULT=",
+		"NESTED=localhost",
+		"OUTER=outer",
+		"CRLF=value",
+	}
+	if !reflect.DeepEqual(parsed, want) {
+		t.Fatalf("want: %q\ngot:  %q", want, parsed)
+	}
+}
+
+func TestParseDotenv_Error(t *testing.T) {
+
+	testSuites := []struct {
+		src  string
+		line string
+	}{
+		{src: "=value", line: "line 1"},
+		{src: "A=1\nKEY", line: "line 2"},
+		{src: "KEY='unterminated", line: "line 1"},
+		{src: "A=1\n\nKEY=\"unterminated\n", line: "line 3"},
+		{src: "KEY=\"value\" trailing", line: "line 1"},
+		{src: "KEY=${UNTERMINATED", line: "line 1"},
+	}
+
+	for i, suite := range testSuites {
+		_, err := env.ParseDotenv(strings.NewReader(suite.src), nil)
+		if err == nil {
+			t.Fatalf("suite:%d %q must be an error", i, suite.src)
+		}
+		if !strings.Contains(err.Error(), suite.line) {
+			t.Fatalf("suite:%d %v", i, err)
+		}
+	}
+}
+
+func TestEnviron(t *testing.T) {
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, ".env")
+	local := filepath.Join(dir, ".env.local")
+	if err := os.WriteFile(base, []byte("ENV_TEST_A=base\nENV_TEST_B=base\nENV_TEST_C=base\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(local, []byte("ENV_TEST_B=local\nENV_TEST_D=${ENV_TEST_A}-${ENV_TEST_C}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = os.Setenv("ENV_TEST_C", "process")
+	t.Cleanup(func() {
+		_ = os.Unsetenv("ENV_TEST_C")
+	})
+
+	environments, err := env.Environ(base, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := env.NewVariableSet("test", env.ContinueOnError)
+	a := set.String("ENV_TEST_A", "", "")
+	b := set.String("ENV_TEST_B", "", "")
+	c := set.String("ENV_TEST_C", "", "")
+	d := set.String("ENV_TEST_D", "", "")
+	if err := set.Parse(environments); err != nil {
+		t.Fatal(err)
+	}
+
+	if *a != "base" || *b != "local" || *c != "process" || *d != "base-process" {
+		t.Fatal(*a, *b, *c, *d)
+	}
+
+	if _, err := env.LoadDotenv(filepath.Join(dir, "missing")); !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	merged := env.Merge([]string{"A=1", "B=1"}, []string{"B=2", "C=2"}, []string{"A=3"})
+	if !reflect.DeepEqual(merged, []string{"A=3", "B=2", "C=2"}) {
+		t.Fatal(merged)
+	}
+}
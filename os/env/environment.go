@@ -1,7 +1,12 @@
 package env
 
 import (
+	"encoding"
+	"net/url"
 	"os"
+	"time"
+
+	"go.nanasi880.dev/x/bytes/byteutil"
 )
 
 // Default is the default set of environment variable set, parsed from os.Environment().
@@ -48,6 +53,176 @@ func BoolVar(p *bool, name string, def bool, usage string) {
 	Default.BoolVar(p, name, def, usage)
 }
 
+// Int64 defines a int64 value with specified name, default value, and usage string.
+// The return value is the address of a int64 variable that stores the value of the environment variable.
+func Int64(name string, def int64, usage string) *int64 {
+	return Default.Int64(name, def, usage)
+}
+
+// Int64Var defines a int64 environment variable with specified name, default value, and usage string.
+// The argument p points to a int64 variable in which to store the value of the environment variable.
+func Int64Var(p *int64, name string, def int64, usage string) {
+	Default.Int64Var(p, name, def, usage)
+}
+
+// Uint defines a uint value with specified name, default value, and usage string.
+// The return value is the address of a uint variable that stores the value of the environment variable.
+func Uint(name string, def uint, usage string) *uint {
+	return Default.Uint(name, def, usage)
+}
+
+// UintVar defines a uint environment variable with specified name, default value, and usage string.
+// The argument p points to a uint variable in which to store the value of the environment variable.
+func UintVar(p *uint, name string, def uint, usage string) {
+	Default.UintVar(p, name, def, usage)
+}
+
+// Float64 defines a float64 value with specified name, default value, and usage string.
+// The return value is the address of a float64 variable that stores the value of the environment variable.
+func Float64(name string, def float64, usage string) *float64 {
+	return Default.Float64(name, def, usage)
+}
+
+// Float64Var defines a float64 environment variable with specified name, default value, and usage string.
+// The argument p points to a float64 variable in which to store the value of the environment variable.
+func Float64Var(p *float64, name string, def float64, usage string) {
+	Default.Float64Var(p, name, def, usage)
+}
+
+// Duration defines a time.Duration value with specified name, default value, and usage string.
+// The return value is the address of a time.Duration variable that stores the value of the environment variable.
+func Duration(name string, def time.Duration, usage string) *time.Duration {
+	return Default.Duration(name, def, usage)
+}
+
+// DurationVar defines a time.Duration environment variable with specified name, default value, and usage string.
+// The argument p points to a time.Duration variable in which to store the value of the environment variable.
+func DurationVar(p *time.Duration, name string, def time.Duration, usage string) {
+	Default.DurationVar(p, name, def, usage)
+}
+
+// Size defines a byteutil.Size value with specified name, default value, and usage string.
+// The return value is the address of a byteutil.Size variable that stores the value of the environment variable.
+func Size(name string, def byteutil.Size, usage string) *byteutil.Size {
+	return Default.Size(name, def, usage)
+}
+
+// SizeVar defines a byteutil.Size environment variable with specified name, default value, and usage string.
+// The argument p points to a byteutil.Size variable in which to store the value of the environment variable.
+func SizeVar(p *byteutil.Size, name string, def byteutil.Size, usage string) {
+	Default.SizeVar(p, name, def, usage)
+}
+
+// Location defines a *time.Location value with specified name, default value, and usage string.
+// The return value is the address of a *time.Location variable that stores the value of the environment variable.
+func Location(name string, def *time.Location, usage string) **time.Location {
+	return Default.Location(name, def, usage)
+}
+
+// LocationVar defines a *time.Location environment variable with specified name, default value, and usage string.
+// The argument p points to a *time.Location variable in which to store the value of the environment variable.
+func LocationVar(p **time.Location, name string, def *time.Location, usage string) {
+	Default.LocationVar(p, name, def, usage)
+}
+
+// URL defines a *url.URL value with specified name, default value, and usage string.
+// The return value is the address of a *url.URL variable that stores the value of the environment variable.
+func URL(name string, def *url.URL, usage string) **url.URL {
+	return Default.URL(name, def, usage)
+}
+
+// URLVar defines a *url.URL environment variable with specified name, default value, and usage string.
+// The argument p points to a *url.URL variable in which to store the value of the environment variable.
+func URLVar(p **url.URL, name string, def *url.URL, usage string) {
+	Default.URLVar(p, name, def, usage)
+}
+
+// StringSlice defines a []string value with specified name, default value, and usage string.
+// The return value is the address of a []string variable that stores the value of the environment variable.
+func StringSlice(name string, def []string, usage string) *[]string {
+	return Default.StringSlice(name, def, usage)
+}
+
+// StringSliceVar defines a []string environment variable with specified name, default value, and usage string.
+// The argument p points to a []string variable in which to store the value of the environment variable.
+func StringSliceVar(p *[]string, name string, def []string, usage string) {
+	Default.StringSliceVar(p, name, def, usage)
+}
+
+// IntSlice defines a []int value with specified name, default value, and usage string.
+// The return value is the address of a []int variable that stores the value of the environment variable.
+func IntSlice(name string, def []int, usage string) *[]int {
+	return Default.IntSlice(name, def, usage)
+}
+
+// IntSliceVar defines a []int environment variable with specified name, default value, and usage string.
+// The argument p points to a []int variable in which to store the value of the environment variable.
+func IntSliceVar(p *[]int, name string, def []int, usage string) {
+	Default.IntSliceVar(p, name, def, usage)
+}
+
+// StringMap defines a map[string]string value with specified name, default value, and usage string.
+// The return value is the address of a map[string]string variable that stores the value of the environment variable.
+func StringMap(name string, def map[string]string, usage string) *map[string]string {
+	return Default.StringMap(name, def, usage)
+}
+
+// StringMapVar defines a map[string]string environment variable with specified name, default value, and usage string.
+// The argument p points to a map[string]string variable in which to store the value of the environment variable.
+func StringMapVar(p *map[string]string, name string, def map[string]string, usage string) {
+	Default.StringMapVar(p, name, def, usage)
+}
+
+// TextVar defines an environment variable with specified name, default value, and usage string.
+// The value is parsed by the UnmarshalText method of p, and the default value is parsed as the same.
+func TextVar(p encoding.TextUnmarshaler, name string, def string, usage string) {
+	Default.TextVar(p, name, def, usage)
+}
+
+// Var defines an environment variable with specified name and usage string.
+// The type and value of the variable are represented by the first argument, of type Value,
+// which typically holds a user-defined implementation of Value.
+func Var(value Value, name string, usage string) {
+	Default.Var(value, name, usage)
+}
+
+// VisitAll visits the variables of Default in lexicographical order, calling fn for each.
+// It visits all variables, even those not set.
+func VisitAll(fn func(*Variable)) {
+	Default.VisitAll(fn)
+}
+
+// Visit visits the variables of Default in lexicographical order, calling fn for each.
+// It visits only those variables that have been set.
+func Visit(fn func(*Variable)) {
+	Default.Visit(fn)
+}
+
+// Lookup returns the Variable of the named environment variable of Default, returning nil if none exists.
+func Lookup(name string) *Variable {
+	return Default.Lookup(name)
+}
+
+// Set sets the value of the named environment variable of Default.
+func Set(name string, value string) error {
+	return Default.Set(name, value)
+}
+
+// Struct defines the environment variables bound to the fields of the struct pointed by v.
+// See VariableSet.Struct for the struct tags.
+func Struct(v interface{}) error {
+	return Default.Struct(v)
+}
+
+// Process defines the environment variables bound to the fields of the struct pointed by v,
+// and parses the environment variables from os.Environ().
+func Process(v interface{}) error {
+	if err := Default.Struct(v); err != nil {
+		return err
+	}
+	return Default.Parse(os.Environ())
+}
+
 // Parse parses the environment variables from os.Environ(). Must be called
 // after all variables are defined and before variable are accessed by the program.
 func Parse() {
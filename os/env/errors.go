@@ -0,0 +1,67 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrRequired is the error of the required variable which is not set.
+	ErrRequired = fmt.Errorf("variable is required")
+)
+
+// VariableError is an error that describes the variable which failed to parse.
+type VariableError struct {
+	Name  string // Name is the name of the environment variable.
+	Value string // Value is the value of the environment variable. If the variable is not set, Value is empty. If the variable is sensitive, Value is redacted.
+	Usage string // Usage is the usage string of the variable.
+	Err   error  // Err is the underlying error. ErrRequired if the required variable is not set.
+}
+
+func (e *VariableError) Error() string {
+	if e.Err == ErrRequired {
+		return fmt.Sprintf("env: %s: %v", e.Name, e.Err)
+	}
+	return fmt.Sprintf("env: %s: invalid value %q: %v", e.Name, e.Value, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *VariableError) Unwrap() error {
+	return e.Err
+}
+
+// ParseErrors is a list of VariableError, returned by VariableSet.Parse.
+// All variables are parsed before the errors are returned, so that all misconfigurations are reported at once.
+type ParseErrors []*VariableError
+
+func (e ParseErrors) Error() string {
+	switch len(e) {
+	case 0:
+		return "env: no errors"
+	case 1:
+		b := new(strings.Builder)
+		b.WriteString(e[0].Error())
+		writeUsage(b, e[0].Usage, "\n\t")
+		return b.String()
+	}
+
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "env: %d errors occurred:", len(e))
+	for _, err := range e {
+		b.WriteString("\n\t")
+		b.WriteString(err.Error())
+		writeUsage(b, err.Usage, "\n\t\t")
+	}
+	return b.String()
+}
+
+// writeUsage writes the lines of the usage string with the prefix.
+func writeUsage(b *strings.Builder, usage string, prefix string) {
+	if usage == "" {
+		return
+	}
+	for _, line := range strings.Split(usage, "\n") {
+		b.WriteString(prefix)
+		b.WriteString(line)
+	}
+}
@@ -0,0 +1,326 @@
+package env
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// binding is the pair of the names of the flag and the environment variable bound to the same value.
+type binding struct {
+	flagName string
+	envName  string
+	usage    string
+}
+
+// A FlagVariableSet defines the flags and the environment variables bound to the same values.
+// The value is resolved with the precedence: the flag, the environment variable, and the default value.
+//
+// The flags are defined in the flag.FlagSet and the environment variables are defined in the VariableSet,
+// so that the flags and the variables which are not bound can be defined as usual.
+// Either name of the flag or the environment variable can be empty to define only the other.
+type FlagVariableSet struct {
+	flags    *flag.FlagSet
+	vars     *VariableSet
+	bindings []binding
+}
+
+// NewFlagVariableSet returns a new FlagVariableSet which defines the flags in flags and the environment variables in vars.
+// The Usage of flags is replaced to print the combined usage.
+func NewFlagVariableSet(flags *flag.FlagSet, vars *VariableSet) *FlagVariableSet {
+	set := &FlagVariableSet{
+		flags: flags,
+		vars:  vars,
+	}
+	flags.Usage = func() {
+		_, _ = fmt.Fprint(flags.Output(), set.Usage())
+	}
+	return set
+}
+
+// FlagSet returns the flag.FlagSet of the flags.
+func (set *FlagVariableSet) FlagSet() *flag.FlagSet {
+	return set.flags
+}
+
+// VariableSet returns the VariableSet of the environment variables.
+func (set *FlagVariableSet) VariableSet() *VariableSet {
+	return set.vars
+}
+
+// String defines a string flag and environment variable with specified names, default value, and usage string.
+// The return value is the address of a string variable that stores the value of the flag or the environment variable.
+func (set *FlagVariableSet) String(flagName string, envName string, def string, usage string) *string {
+	p := new(string)
+	set.StringVar(p, flagName, envName, def, usage)
+	return p
+}
+
+// StringVar defines a string flag and environment variable with specified names, default value, and usage string.
+// The argument p points to a string variable in which to store the value of the flag or the environment variable.
+func (set *FlagVariableSet) StringVar(p *string, flagName string, envName string, def string, usage string) {
+	if flagName != "" {
+		set.flags.StringVar(p, flagName, def, usage)
+	}
+	if envName != "" {
+		set.vars.StringVar(p, envName, def, usage)
+	}
+	set.bind(flagName, envName, usage)
+}
+
+// Int defines an int flag and environment variable with specified names, default value, and usage string.
+// The return value is the address of an int variable that stores the value of the flag or the environment variable.
+func (set *FlagVariableSet) Int(flagName string, envName string, def int, usage string) *int {
+	p := new(int)
+	set.IntVar(p, flagName, envName, def, usage)
+	return p
+}
+
+// IntVar defines an int flag and environment variable with specified names, default value, and usage string.
+// The argument p points to an int variable in which to store the value of the flag or the environment variable.
+func (set *FlagVariableSet) IntVar(p *int, flagName string, envName string, def int, usage string) {
+	if flagName != "" {
+		set.flags.IntVar(p, flagName, def, usage)
+	}
+	if envName != "" {
+		set.vars.IntVar(p, envName, def, usage)
+	}
+	set.bind(flagName, envName, usage)
+}
+
+// Int64 defines an int64 flag and environment variable with specified names, default value, and usage string.
+// The return value is the address of an int64 variable that stores the value of the flag or the environment variable.
+func (set *FlagVariableSet) Int64(flagName string, envName string, def int64, usage string) *int64 {
+	p := new(int64)
+	set.Int64Var(p, flagName, envName, def, usage)
+	return p
+}
+
+// Int64Var defines an int64 flag and environment variable with specified names, default value, and usage string.
+// The argument p points to an int64 variable in which to store the value of the flag or the environment variable.
+func (set *FlagVariableSet) Int64Var(p *int64, flagName string, envName string, def int64, usage string) {
+	if flagName != "" {
+		set.flags.Int64Var(p, flagName, def, usage)
+	}
+	if envName != "" {
+		set.vars.Int64Var(p, envName, def, usage)
+	}
+	set.bind(flagName, envName, usage)
+}
+
+// Uint defines a uint flag and environment variable with specified names, default value, and usage string.
+// The return value is the address of a uint variable that stores the value of the flag or the environment variable.
+func (set *FlagVariableSet) Uint(flagName string, envName string, def uint, usage string) *uint {
+	p := new(uint)
+	set.UintVar(p, flagName, envName, def, usage)
+	return p
+}
+
+// UintVar defines a uint flag and environment variable with specified names, default value, and usage string.
+// The argument p points to a uint variable in which to store the value of the flag or the environment variable.
+func (set *FlagVariableSet) UintVar(p *uint, flagName string, envName string, def uint, usage string) {
+	if flagName != "" {
+		set.flags.UintVar(p, flagName, def, usage)
+	}
+	if envName != "" {
+		set.vars.UintVar(p, envName, def, usage)
+	}
+	set.bind(flagName, envName, usage)
+}
+
+// Float64 defines a float64 flag and environment variable with specified names, default value, and usage string.
+// The return value is the address of a float64 variable that stores the value of the flag or the environment variable.
+func (set *FlagVariableSet) Float64(flagName string, envName string, def float64, usage string) *float64 {
+	p := new(float64)
+	set.Float64Var(p, flagName, envName, def, usage)
+	return p
+}
+
+// Float64Var defines a float64 flag and environment variable with specified names, default value, and usage string.
+// The argument p points to a float64 variable in which to store the value of the flag or the environment variable.
+func (set *FlagVariableSet) Float64Var(p *float64, flagName string, envName string, def float64, usage string) {
+	if flagName != "" {
+		set.flags.Float64Var(p, flagName, def, usage)
+	}
+	if envName != "" {
+		set.vars.Float64Var(p, envName, def, usage)
+	}
+	set.bind(flagName, envName, usage)
+}
+
+// Bool defines a bool flag and environment variable with specified names, default value, and usage string.
+// The return value is the address of a bool variable that stores the value of the flag or the environment variable.
+func (set *FlagVariableSet) Bool(flagName string, envName string, def bool, usage string) *bool {
+	p := new(bool)
+	set.BoolVar(p, flagName, envName, def, usage)
+	return p
+}
+
+// BoolVar defines a bool flag and environment variable with specified names, default value, and usage string.
+// The argument p points to a bool variable in which to store the value of the flag or the environment variable.
+func (set *FlagVariableSet) BoolVar(p *bool, flagName string, envName string, def bool, usage string) {
+	if flagName != "" {
+		set.flags.BoolVar(p, flagName, def, usage)
+	}
+	if envName != "" {
+		set.vars.BoolVar(p, envName, def, usage)
+	}
+	set.bind(flagName, envName, usage)
+}
+
+// Duration defines a time.Duration flag and environment variable with specified names, default value, and usage string.
+// The return value is the address of a time.Duration variable that stores the value of the flag or the environment variable.
+func (set *FlagVariableSet) Duration(flagName string, envName string, def time.Duration, usage string) *time.Duration {
+	p := new(time.Duration)
+	set.DurationVar(p, flagName, envName, def, usage)
+	return p
+}
+
+// DurationVar defines a time.Duration flag and environment variable with specified names, default value, and usage string.
+// The argument p points to a time.Duration variable in which to store the value of the flag or the environment variable.
+func (set *FlagVariableSet) DurationVar(p *time.Duration, flagName string, envName string, def time.Duration, usage string) {
+	if flagName != "" {
+		set.flags.DurationVar(p, flagName, def, usage)
+	}
+	if envName != "" {
+		set.vars.DurationVar(p, envName, def, usage)
+	}
+	set.bind(flagName, envName, usage)
+}
+
+// Var defines a flag and an environment variable with specified names and usage string.
+// The type and value are represented by the first argument, of type flag.Value, which implements Value too.
+func (set *FlagVariableSet) Var(value flag.Value, flagName string, envName string, usage string) {
+	if flagName != "" {
+		set.flags.Var(value, flagName, usage)
+	}
+	if envName != "" {
+		set.vars.Var(value, envName, usage)
+	}
+	set.bind(flagName, envName, usage)
+}
+
+// Parse parses the command-line flags from args, and then the environment variables from environments.
+// The environment variables bound to the flags which are set in args are not parsed, so that the flags take precedence,
+// and they are regarded as set, e.g. by Required and Visit.
+// The errors are handled by the error handling properties of flag.FlagSet and VariableSet respectively.
+func (set *FlagVariableSet) Parse(args []string, environments []string) error {
+
+	if err := set.flags.Parse(args); err != nil {
+		return err
+	}
+
+	overridden := make(map[string]bool)
+	set.flags.Visit(func(f *flag.Flag) {
+		for _, b := range set.bindings {
+			if b.flagName == f.Name && b.envName != "" {
+				overridden[b.envName] = true
+			}
+		}
+	})
+
+	return set.vars.parse(environments, overridden)
+}
+
+// Usage returns the combined usage of the flags and the environment variables.
+// The bound flag and environment variable are listed together, e.g. "-port, PORT".
+func (set *FlagVariableSet) Usage() string {
+
+	type entry struct {
+		names string
+		key   string
+		def   string
+		usage string
+	}
+
+	var (
+		entries  []entry
+		flagSeen = make(map[string]bool)
+		envSeen  = make(map[string]bool)
+	)
+	for _, b := range set.bindings {
+		e := entry{usage: b.usage}
+		switch {
+		case b.flagName != "" && b.envName != "":
+			e.names, e.key = "-"+b.flagName+", "+b.envName, b.flagName
+		case b.flagName != "":
+			e.names, e.key = "-"+b.flagName, b.flagName
+		default:
+			e.names, e.key = b.envName, b.envName
+		}
+		if b.envName != "" {
+			e.def = set.envDefault(b.envName)
+		}
+		if e.def == "" && b.flagName != "" {
+			e.def = set.flagDefault(b.flagName)
+		}
+		flagSeen[b.flagName] = true
+		envSeen[b.envName] = true
+		entries = append(entries, e)
+	}
+
+	set.flags.VisitAll(func(f *flag.Flag) {
+		if flagSeen[f.Name] {
+			return
+		}
+		entries = append(entries, entry{names: "-" + f.Name, key: f.Name, def: set.flagDefault(f.Name), usage: f.Usage})
+	})
+	for _, key := range set.vars.sortedKeys() {
+		if envSeen[key] {
+			continue
+		}
+		v := set.vars.variables[key]
+		entries = append(entries, entry{names: key, key: key, def: set.envDefault(key), usage: v.usage})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return strings.ToLower(entries[i].key) < strings.ToLower(entries[j].key)
+	})
+
+	var sb strings.Builder
+	if name := set.flags.Name(); name != "" {
+		set.vars.fprintf(&sb, "Usage of %s\n", name)
+	}
+	for _, e := range entries {
+		if e.def != "" {
+			set.vars.fprintf(&sb, "  %s: %s\n", e.names, e.def)
+		} else {
+			set.vars.fprintf(&sb, "  %s:\n", e.names)
+		}
+		for _, line := range strings.Split(e.usage, "\n") {
+			set.vars.fprintf(&sb, "    %s\n", line)
+		}
+	}
+	return sb.String()
+}
+
+func (set *FlagVariableSet) bind(flagName string, envName string, usage string) {
+	if flagName == "" && envName == "" {
+		panic("either `flagName` or `envName` must be specified")
+	}
+	set.bindings = append(set.bindings, binding{
+		flagName: flagName,
+		envName:  envName,
+		usage:    usage,
+	})
+}
+
+// envDefault returns the description of the default value of the environment variable, as the same as VariableSet.Usage.
+func (set *FlagVariableSet) envDefault(name string) string {
+	v := set.vars.variables[name]
+	if v == nil {
+		return ""
+	}
+	return v.describe()
+}
+
+// flagDefault returns the description of the default value of the flag.
+func (set *FlagVariableSet) flagDefault(name string) string {
+	f := set.flags.Lookup(name)
+	if f == nil {
+		return ""
+	}
+	return fmt.Sprintf("default(%s)", f.DefValue)
+}
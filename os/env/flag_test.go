@@ -0,0 +1,124 @@
+package env_test
+
+import (
+	"flag"
+	"io"
+	"testing"
+	"time"
+
+	"go.nanasi880.dev/x/os/env"
+)
+
+func newFlagVariableSet() *env.FlagVariableSet {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return env.NewFlagVariableSet(flags, env.NewVariableSet("test", env.ContinueOnError))
+}
+
+func TestFlagVariableSet_Parse(t *testing.T) {
+
+	set := newFlagVariableSet()
+	port := set.Int("port", "PORT", 8080, "listen port")
+	host := set.String("host", "HOST", "localhost", "listen host")
+	timeout := set.Duration("timeout", "TIMEOUT", time.Second, "timeout")
+	debug := set.Bool("debug", "", false, "flag only")
+	token := set.String("", "TOKEN", "", "env only")
+
+	err := set.Parse(
+		[]string{"-port", "9090", "-debug"},
+		[]string{"PORT=7070", "HOST=example.com", "TOKEN=x"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *port != 9090 {
+		t.Fatal("flag must take precedence over env", *port)
+	}
+	if *host != "example.com" {
+		t.Fatal("env must take precedence over default", *host)
+	}
+	if *timeout != time.Second {
+		t.Fatal(*timeout)
+	}
+	if !*debug || *token != "x" {
+		t.Fatal(*debug, *token)
+	}
+}
+
+func TestFlagVariableSet_Parse_Error(t *testing.T) {
+
+	set := newFlagVariableSet()
+	set.Int("port", "PORT", 8080, "listen port")
+
+	if err := set.Parse([]string{"-port", "x"}, nil); err == nil {
+		t.Fatal("invalid flag must be an error")
+	}
+
+	set = newFlagVariableSet()
+	set.Int("port", "PORT", 8080, "listen port")
+	if err := set.Parse(nil, []string{"PORT=x"}); err == nil {
+		t.Fatal("invalid env must be an error")
+	}
+
+	// the invalid env is not parsed if the flag is set
+	set = newFlagVariableSet()
+	set.Int("port", "PORT", 8080, "listen port")
+	if err := set.Parse([]string{"-port=1"}, []string{"PORT=x"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFlagVariableSet_Parse_Required(t *testing.T) {
+
+	set := newFlagVariableSet()
+	port := set.Int("port", "PORT", 8080, "listen port")
+	set.VariableSet().Required("PORT")
+
+	if err := set.Parse([]string{"-port", "80"}, nil); err != nil {
+		t.Fatal("the flag must satisfy the required variable", err)
+	}
+	if *port != 80 {
+		t.Fatal(*port)
+	}
+	visited := false
+	set.VariableSet().Visit(func(v *env.Variable) {
+		visited = visited || v.Name == "PORT"
+	})
+	if !visited {
+		t.Fatal("the variable set by the flag must be visited")
+	}
+
+	set = newFlagVariableSet()
+	set.Int("port", "PORT", 8080, "listen port")
+	set.VariableSet().Required("PORT")
+	if err := set.Parse(nil, nil); err == nil {
+		t.Fatal("required variable must be reported")
+	}
+}
+
+func TestFlagVariableSet_Usage(t *testing.T) {
+
+	set := newFlagVariableSet()
+	set.Int("port", "PORT", 8080, "listen port")
+	set.Bool("debug", "", false, "debug mode")
+	set.String("", "TOKEN", "", "api token")
+	set.VariableSet().Required("TOKEN")
+	set.FlagSet().String("config", "app.yaml", "unbound flag")
+	set.VariableSet().String("LOG_LEVEL", "info", "unbound env")
+
+	const want = "Usage of test\n" +
+		"  -config: default(app.yaml)\n" +
+		"    unbound flag\n" +
+		"  -debug: default(false)\n" +
+		"    debug mode\n" +
+		"  LOG_LEVEL: default(info)\n" +
+		"    unbound env\n" +
+		"  -port, PORT: default(8080)\n" +
+		"    listen port\n" +
+		"  TOKEN: required\n" +
+		"    api token\n"
+	if usage := set.Usage(); usage != want {
+		t.Fatalf("want: %q got: %q", want, usage)
+	}
+}
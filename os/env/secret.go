@@ -0,0 +1,106 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.nanasi880.dev/x/bytes/byteutil"
+)
+
+const (
+	defaultMaxFileSize = 64 * byteutil.KiB
+
+	// Redacted is the placeholder of the value of the sensitive variable.
+	Redacted = "******"
+)
+
+// Sensitive marks the defined variables as sensitive. The values of the sensitive variables are redacted in Usage and the errors.
+func (set *VariableSet) Sensitive(names ...string) {
+	for _, name := range names {
+		val, ok := set.variables[name]
+		if !ok {
+			msg := fmt.Sprintf("%s is not defined", name)
+			panic(msg)
+		}
+		val.sensitive = true
+	}
+}
+
+// lookupFile returns the contents of the file specified by the variable name + FileSuffix.
+// The second return value is whether the variable of the file is set.
+func (set *VariableSet) lookupFile(environments []string, key string) (string, bool, *VariableError) {
+
+	if set.FileSuffix == "" {
+		return "", false, nil
+	}
+
+	fileKey := key + set.FileSuffix
+	path, found := set.lookup(environments, fileKey)
+	if !found {
+		return "", false, nil
+	}
+
+	if _, found := set.lookup(environments, key); found {
+		return "", true, &VariableError{
+			Name:  fileKey,
+			Value: path,
+			Err:   fmt.Errorf("%s is also set", key),
+		}
+	}
+
+	content, err := set.readFile(path)
+	if err != nil {
+		return "", true, &VariableError{
+			Name:  fileKey,
+			Value: path,
+			Err:   err,
+		}
+	}
+	return content, true, nil
+}
+
+// readFile reads the file up to MaxFileSize, and trims the leading and trailing white spaces.
+func (set *VariableSet) readFile(path string) (string, error) {
+
+	maxSize := set.MaxFileSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxFileSize
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	b, err := io.ReadAll(io.LimitReader(f, maxSize.Int64()+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(b)) > maxSize.Int64() {
+		return "", fmt.Errorf("the file size exceeds %d bytes", maxSize.Int64())
+	}
+
+	return strings.TrimSpace(string(b)), nil
+}
+
+// redactedError is the error of the sensitive variable, whose message does not contain the value.
+type redactedError struct {
+	err   error
+	value string
+}
+
+func (e *redactedError) Error() string {
+	if e.value == "" {
+		return e.err.Error()
+	}
+	return strings.ReplaceAll(e.err.Error(), e.value, Redacted)
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
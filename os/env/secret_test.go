@@ -0,0 +1,107 @@
+package env_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.nanasi880.dev/x/os/env"
+)
+
+func TestVariableSet_FileSuffix(t *testing.T) {
+
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "db")
+	if err := os.WriteFile(secret, []byte("s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	large := filepath.Join(dir, "large")
+	if err := os.WriteFile(large, []byte(strings.Repeat("x", 17)), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	newSet := func() (*env.VariableSet, *string) {
+		set := env.NewVariableSet("test", env.ContinueOnError)
+		set.FileSuffix = "_FILE"
+		set.MaxFileSize = 16
+		password := set.String("DB_PASSWORD", "", "database password")
+		set.Required("DB_PASSWORD")
+		return set, password
+	}
+
+	t.Run("File", func(t *testing.T) {
+		set, password := newSet()
+		if err := set.Parse([]string{"DB_PASSWORD_FILE=" + secret}); err != nil {
+			t.Fatal(err)
+		}
+		if *password != "s3cret" {
+			t.Fatal(*password)
+		}
+	})
+
+	t.Run("Value", func(t *testing.T) {
+		set, password := newSet()
+		if err := set.Parse([]string{"DB_PASSWORD=plain"}); err != nil {
+			t.Fatal(err)
+		}
+		if *password != "plain" {
+			t.Fatal(*password)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		testSuites := [][]string{
+			{"DB_PASSWORD_FILE=" + filepath.Join(dir, "missing")},
+			{"DB_PASSWORD_FILE=" + large},
+			{"DB_PASSWORD_FILE=" + secret, "DB_PASSWORD=plain"},
+		}
+		for i, suite := range testSuites {
+			set, _ := newSet()
+			err := set.Parse(suite)
+
+			var errs env.ParseErrors
+			if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Name != "DB_PASSWORD_FILE" {
+				t.Fatalf("suite:%d %v", i, err)
+			}
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		set, _ := newSet()
+		set.FileSuffix = ""
+		if err := set.Parse([]string{"DB_PASSWORD_FILE=" + secret}); err == nil {
+			t.Fatal("DB_PASSWORD must be required")
+		}
+	})
+}
+
+func TestVariableSet_Sensitive(t *testing.T) {
+
+	var cfg struct {
+		APIKey string `env:"API_KEY" default:"default-key" sensitive:"true"`
+	}
+	set := env.NewVariableSet("test", env.ContinueOnError)
+	if err := set.Struct(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	port := set.Int("PORT", 8080, "")
+	set.Sensitive("PORT")
+
+	usage := set.Usage()
+	if strings.Contains(usage, "default-key") || strings.Contains(usage, "8080") {
+		t.Fatal(usage)
+	}
+	if !strings.Contains(usage, "API_KEY: default("+env.Redacted+")") {
+		t.Fatal(usage)
+	}
+
+	err := set.Parse([]string{"PORT=secret-port"})
+	if err == nil || strings.Contains(err.Error(), "secret-port") {
+		t.Fatal(err)
+	}
+	if *port != 8080 {
+		t.Fatal(*port)
+	}
+}
@@ -0,0 +1,242 @@
+package env
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.nanasi880.dev/x/bytes/byteutil"
+)
+
+// Struct defines the environment variables bound to the fields of the struct pointed by v.
+//
+// The field is configured by the struct tags:
+//
+//	env:"PORT"         the name of the environment variable. "-" ignores the field.
+//	default:"8080"     the default value, which is parsed as the same as the environment variable.
+//	usage:"..."        the usage string.
+//	required:"true"    the environment variable must be set.
+//	sensitive:"true"   the value is redacted in Usage and the errors.
+//	sep:";"            the separator of the slice and map, instead of Separator.
+//
+// The supported field types are the types of the variables defined by VariableSet, e.g. string, int, time.Duration and []string,
+// and the types whose pointer implements Value or encoding.TextUnmarshaler.
+// If the env tag is omitted, the name is the field name converted to the upper snake case, e.g. DatabaseURL is DATABASE_URL.
+// The fields of the nested struct are named with the prefix of the env tag (or the field name) and "_",
+// and the fields of the embedded struct without the env tag are promoted to the parent.
+// The nil pointer of the nested struct is allocated.
+// The struct of the standard library, e.g. tls.Config, is not nested, and the recursive struct type is an error.
+func (set *VariableSet) Struct(v interface{}) error {
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("`v` must be a non-nil pointer to struct: %T", v)
+	}
+
+	return set.structFields(rv.Elem(), "", []reflect.Type{rv.Elem().Type()})
+}
+
+func (set *VariableSet) structFields(rv reflect.Value, prefix string, visited []reflect.Type) error {
+
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+
+		sf := rt.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			// unexported
+			continue
+		}
+
+		tag, tagged := sf.Tag.Lookup("env")
+		if tag == "-" {
+			continue
+		}
+
+		fv := rv.Field(i)
+		// the unexported embedded field cannot be addressed, so only the nested struct is bound
+		if sf.PkgPath == "" {
+			if v, ok := set.structValue(fv, sf); ok {
+				if err := set.structField(v, fv, sf, prefix+set.fieldName(sf, tag)); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if nestedType, nested, ok := set.nestedStruct(fv); ok {
+			if sf.PkgPath != "" && fv.Kind() == reflect.Ptr {
+				// unexported embedded pointer cannot be allocated
+				continue
+			}
+			if containsType(visited, nestedType) {
+				return fmt.Errorf("field %s: recursive type: %s", sf.Name, sf.Type)
+			}
+			nestedPrefix := prefix
+			if tagged || !sf.Anonymous {
+				nestedPrefix = prefix + set.fieldName(sf, tag) + "_"
+			}
+			if err := set.structFields(nested(), nestedPrefix, append(visited, nestedType)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if sf.PkgPath != "" {
+			// unexported embedded non-struct type
+			continue
+		}
+
+		return fmt.Errorf("field %s: unsupported type: %s", sf.Name, sf.Type)
+	}
+
+	return nil
+}
+
+func (set *VariableSet) structField(v Value, fv reflect.Value, sf reflect.StructField, name string) error {
+
+	var (
+		usage     = sf.Tag.Get("usage")
+		required  = sf.Tag.Get("required") == "true"
+		sensitive = sf.Tag.Get("sensitive") == "true"
+		def       interface{}
+	)
+	if tag, ok := sf.Tag.Lookup("default"); ok {
+		if err := v.Set(tag); err != nil {
+			return fmt.Errorf("field %s: invalid default value `%s`: %w", sf.Name, tag, err)
+		}
+		if !required {
+			// only the default tag is shown as the default value, not the zero value of the untagged field
+			def = fv.Interface()
+		}
+	}
+
+	variable := set.value(v, def, name, usage)
+	variable.required = required
+	variable.sensitive = sensitive
+
+	return nil
+}
+
+// nestedStruct returns the struct type and the function that returns the struct value of fv if fv is a struct or a pointer to struct.
+// The function allocates the nil pointer, so it is called only if the fields are bound.
+// The struct of the standard library is not nested, because it is not declared for the configuration.
+func (_ *VariableSet) nestedStruct(fv reflect.Value) (reflect.Type, func() reflect.Value, bool) {
+
+	t := fv.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || isStandardPackage(t.PkgPath()) {
+		return nil, nil, false
+	}
+
+	if fv.Kind() == reflect.Struct {
+		return t, func() reflect.Value { return fv }, true
+	}
+	return t, func() reflect.Value {
+		if fv.IsNil() {
+			fv.Set(reflect.New(t))
+		}
+		return fv.Elem()
+	}, true
+}
+
+// isStandardPackage returns whether the package is in the standard library, whose first path element does not contain a dot.
+// The anonymous struct has the empty package path, and the package main is not in the standard library.
+func isStandardPackage(pkgPath string) bool {
+	if pkgPath == "" || pkgPath == "main" {
+		return false
+	}
+	elem := pkgPath
+	if i := strings.IndexByte(elem, '/'); i >= 0 {
+		elem = elem[:i]
+	}
+	return !strings.Contains(elem, ".")
+}
+
+func containsType(types []reflect.Type, t reflect.Type) bool {
+	for _, typ := range types {
+		if typ == t {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldName returns the name of the environment variable of the field.
+func (_ *VariableSet) fieldName(sf reflect.StructField, tag string) string {
+	if tag != "" {
+		return tag
+	}
+	return upperSnakeCase(sf.Name)
+}
+
+// structValue returns the Value bound to the struct field.
+func (set *VariableSet) structValue(fv reflect.Value, sf reflect.StructField) (Value, bool) {
+
+	sep, ok := sf.Tag.Lookup("sep")
+	if !ok {
+		sep = set.separator()
+	}
+
+	switch p := fv.Addr().Interface().(type) {
+	case *string:
+		return newStringValue(p), true
+	case *int:
+		return newIntValue(p), true
+	case *bool:
+		return newBoolValue(p), true
+	case *int64:
+		return newInt64Value(p), true
+	case *uint:
+		return newUintValue(p), true
+	case *float64:
+		return newFloat64Value(p), true
+	case *time.Duration:
+		return newDurationValue(p), true
+	case *byteutil.Size:
+		return newSizeValue(p), true
+	case **time.Location:
+		return newLocationValue(p), true
+	case **url.URL:
+		return newURLValue(p), true
+	case *url.URL:
+		return newURLStructValue(p), true
+	case *[]string:
+		return newStringSliceValue(p, sep), true
+	case *[]int:
+		return newIntSliceValue(p, sep), true
+	case *map[string]string:
+		return newStringMapValue(p, sep), true
+	case Value:
+		return p, true
+	case encoding.TextUnmarshaler:
+		return newTextValue(p), true
+	default:
+		return nil, false
+	}
+}
+
+// upperSnakeCase converts the Go identifier to the upper snake case, e.g. DatabaseURL to DATABASE_URL.
+func upperSnakeCase(s string) string {
+
+	var (
+		sb    strings.Builder
+		runes = []rune(s)
+	)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				sb.WriteByte('_')
+			}
+		}
+		sb.WriteRune(unicode.ToUpper(r))
+	}
+	return sb.String()
+}
@@ -0,0 +1,183 @@
+package env_test
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+
+	"go.nanasi880.dev/x/os/env"
+)
+
+func TestVariableSet_Struct(t *testing.T) {
+
+	type Database struct {
+		Host string `default:"localhost" usage:"database host"`
+		Port int    `default:"5432"`
+	}
+	type Logging struct {
+		Verbose bool `env:"LOG_VERBOSE"`
+	}
+	type config struct {
+		Logging
+		Port        int       `env:"PORT" default:"8080" usage:"listen port"`
+		DatabaseURL string    `required:"true"`
+		Primary     Database  `env:"PRIMARY"`
+		Replica     *Database `env:"REPLICA"`
+		Ignored     string    `env:"-"`
+		unexported  string
+	}
+
+	var cfg config
+	set := env.NewVariableSet("test", env.ContinueOnError)
+	if err := set.Struct(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != 8080 || cfg.Primary.Host != "localhost" || cfg.Replica == nil || cfg.Replica.Port != 5432 {
+		t.Fatalf("%+v", cfg)
+	}
+
+	err := set.Parse([]string{
+		"PORT=9090",
+		"DATABASE_URL=postgres://",
+		"PRIMARY_HOST=db1",
+		"REPLICA_PORT=5433",
+		"LOG_VERBOSE=true",
+		"IGNORED=x",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := config{
+		Logging:     Logging{Verbose: true},
+		Port:        9090,
+		DatabaseURL: "postgres://",
+		Primary:     Database{Host: "db1", Port: 5432},
+	}
+	if cfg.Replica == nil || *cfg.Replica != (Database{Host: "localhost", Port: 5433}) {
+		t.Fatalf("%+v", cfg.Replica)
+	}
+	cfg.Replica = nil
+	if cfg != want {
+		t.Fatalf("%+v", cfg)
+	}
+
+	usage := set.Usage()
+	for _, line := range []string{
+		"  DATABASE_URL: required\n",
+		"  PORT: default(8080)\n    listen port\n",
+		"  PRIMARY_HOST: default(localhost)\n    database host\n",
+		"  LOG_VERBOSE:\n",
+	} {
+		if !strings.Contains(usage, line) {
+			t.Fatalf("%q is not contained in %q", line, usage)
+		}
+	}
+}
+
+type embedded struct {
+	Host string `default:"localhost"`
+}
+
+func TestVariableSet_Struct_UnexportedEmbedded(t *testing.T) {
+
+	var cfg struct {
+		embedded
+		Port int
+	}
+	set := env.NewVariableSet("test", env.ContinueOnError)
+	if err := set.Struct(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Parse([]string{"HOST=db1", "PORT=80"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "db1" || cfg.Port != 80 {
+		t.Fatalf("%+v", cfg)
+	}
+}
+
+type node struct {
+	Name string
+	Next *node
+}
+
+func TestVariableSet_Struct_Recursive(t *testing.T) {
+
+	var n node
+	set := env.NewVariableSet("test", env.ContinueOnError)
+	if err := set.Struct(&n); err == nil {
+		t.Fatal("recursive type must be rejected")
+	}
+}
+
+func TestVariableSet_Struct_URL(t *testing.T) {
+
+	var cfg struct {
+		Endpoint url.URL  `default:"http://localhost"`
+		Proxy    *url.URL `env:"PROXY"`
+	}
+	set := env.NewVariableSet("test", env.ContinueOnError)
+	if err := set.Struct(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Endpoint.Host != "localhost" {
+		t.Fatal(cfg.Endpoint)
+	}
+	if err := set.Parse([]string{"ENDPOINT=https://example.com/api", "PROXY=http://proxy"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Endpoint.String() != "https://example.com/api" || cfg.Proxy == nil || cfg.Proxy.Host != "proxy" {
+		t.Fatal(cfg.Endpoint, cfg.Proxy)
+	}
+	if v := set.Lookup("ENDPOINT"); v == nil || v.DefValue != "http://localhost" {
+		t.Fatal(v)
+	}
+
+	// the struct of the standard library is not flattened
+	var addr struct {
+		Addr net.TCPAddr
+	}
+	if err := set.Struct(&addr); err == nil {
+		t.Fatal("the struct of the standard library must be rejected")
+	}
+}
+
+func TestVariableSet_Struct_Required(t *testing.T) {
+
+	var cfg struct {
+		Token string `env:"TOKEN" required:"true"`
+	}
+	set := env.NewVariableSet("test", env.ContinueOnError)
+	if err := set.Struct(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Parse(nil); err == nil {
+		t.Fatal("required variable must be reported")
+	}
+}
+
+func TestVariableSet_Struct_Error(t *testing.T) {
+
+	set := env.NewVariableSet("test", env.ContinueOnError)
+
+	var notStruct int
+	if err := set.Struct(&notStruct); err == nil {
+		t.Fatal("non-struct must be rejected")
+	}
+
+	var unsupported struct {
+		C chan int
+	}
+	if err := set.Struct(&unsupported); err == nil {
+		t.Fatal("unsupported type must be rejected")
+	}
+
+	var invalidDefault struct {
+		N int `default:"x"`
+	}
+	if err := set.Struct(&invalidDefault); err == nil {
+		t.Fatal("invalid default must be rejected")
+	}
+}
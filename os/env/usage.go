@@ -0,0 +1,123 @@
+package env
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// usageJSON is the JSON representation of a variable.
+type usageJSON struct {
+	Name      string  `json:"name"`
+	Default   *string `json:"default,omitempty"`
+	Required  bool    `json:"required"`
+	Sensitive bool    `json:"sensitive"`
+	Usage     string  `json:"usage"`
+}
+
+// UsageMarkdown returns the usage of the variables as a Markdown table.
+// The default values of the sensitive variables are redacted.
+func (set *VariableSet) UsageMarkdown() string {
+
+	var sb strings.Builder
+	sb.WriteString("| Name | Default | Required | Description |\n")
+	sb.WriteString("| --- | --- | --- | --- |\n")
+
+	set.VisitAll(func(v *Variable) {
+		def := ""
+		if v.HasDef && v.DefValue != "" {
+			def = codeSpan(v.DefValue)
+		}
+		required := "no"
+		if v.Required {
+			required = "yes"
+		}
+		set.fprintf(&sb, "| `%s` | %s | %s | %s |\n", v.Name, escapeMarkdownCell(def), required, escapeMarkdownCell(v.Usage))
+	})
+
+	return sb.String()
+}
+
+// UsageJSON returns the usage of the variables as a JSON array.
+// The default value is omitted if the variable has no default value, and it is redacted if the variable is sensitive.
+func (set *VariableSet) UsageJSON() ([]byte, error) {
+
+	variables := make([]usageJSON, 0, len(set.variables))
+	set.VisitAll(func(v *Variable) {
+		u := usageJSON{
+			Name:      v.Name,
+			Default:   nil,
+			Required:  v.Required,
+			Sensitive: v.Sensitive,
+			Usage:     v.Usage,
+		}
+		if v.HasDef {
+			def := v.DefValue
+			u.Default = &def
+		}
+		variables = append(variables, u)
+	})
+
+	return json.MarshalIndent(variables, "", "  ")
+}
+
+// UsageDotenv returns the sample .env file of the variables, which is parsed by ParseDotenv.
+// The usage is written as the comment, and the value is the default value.
+// The values of the required variables and the sensitive variables are left empty.
+func (set *VariableSet) UsageDotenv() string {
+
+	var (
+		sb    strings.Builder
+		first = true
+	)
+	set.VisitAll(func(v *Variable) {
+		if !first {
+			sb.WriteByte('\n')
+		}
+		first = false
+
+		if v.Usage != "" {
+			for _, line := range strings.Split(v.Usage, "\n") {
+				set.fprintf(&sb, "# %s\n", line)
+			}
+		}
+		switch {
+		case v.Required && v.Sensitive:
+			sb.WriteString("# (required, sensitive)\n")
+		case v.Required:
+			sb.WriteString("# (required)\n")
+		case v.Sensitive:
+			sb.WriteString("# (sensitive)\n")
+		}
+
+		value := ""
+		if v.HasDef && !v.Sensitive {
+			value = quoteDotenv(v.DefValue)
+		}
+		set.fprintf(&sb, "%s=%s\n", v.Name, value)
+	})
+
+	return sb.String()
+}
+
+// codeSpan returns the Markdown code span of s. The backticks in s are enclosed by the double backticks.
+func codeSpan(s string) string {
+	if strings.Contains(s, "`") {
+		return "`` " + s + " ``"
+	}
+	return "`" + s + "`"
+}
+
+// escapeMarkdownCell escapes the pipes and the line breaks in the cell of the Markdown table.
+func escapeMarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// quoteDotenv quotes the value for the dotenv format if needed.
+func quoteDotenv(s string) string {
+	if !strings.ContainsAny(s, " \t\r\n#'\"\\$") {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
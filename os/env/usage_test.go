@@ -0,0 +1,170 @@
+package env_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.nanasi880.dev/x/os/env"
+)
+
+func newUsageTestSet() *env.VariableSet {
+	set := env.NewVariableSet("test", env.ContinueOnError)
+	set.Int("PORT", 8080, "listen port")
+	set.String("DATABASE_URL", "", "database connection string")
+	set.String("API_KEY", "default-key", "api key")
+	set.StringSlice("HOSTS", []string{"a", "b"}, "allowed hosts\nseparated by comma")
+	set.String("GREETING", "hello | \"world\"", "")
+	set.Duration("TIMEOUT", 30*time.Second, "request timeout")
+	set.Required("DATABASE_URL")
+	set.Sensitive("API_KEY")
+	return set
+}
+
+func TestVariableSet_Visit(t *testing.T) {
+
+	set := newUsageTestSet()
+	if err := set.Parse([]string{"PORT=9090", "DATABASE_URL=postgres://"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Set("TIMEOUT", "1m"); err != nil {
+		t.Fatal(err)
+	}
+
+	var visited []string
+	set.Visit(func(v *env.Variable) {
+		visited = append(visited, v.Name)
+	})
+	if !reflect.DeepEqual(visited, []string{"DATABASE_URL", "PORT", "TIMEOUT"}) {
+		t.Fatal(visited)
+	}
+
+	var all []string
+	set.VisitAll(func(v *env.Variable) {
+		all = append(all, v.Name)
+	})
+	if !reflect.DeepEqual(all, []string{"API_KEY", "DATABASE_URL", "GREETING", "HOSTS", "PORT", "TIMEOUT"}) {
+		t.Fatal(all)
+	}
+
+	port := set.Lookup("PORT")
+	if port == nil || port.DefValue != "8080" || !port.HasDef || port.Usage != "listen port" {
+		t.Fatalf("%+v", port)
+	}
+	if s, ok := port.Value.(interface{ String() string }); !ok || s.String() != "9090" {
+		t.Fatalf("%+v", port.Value)
+	}
+	if key := set.Lookup("API_KEY"); key.DefValue != env.Redacted || !key.Sensitive {
+		t.Fatalf("%+v", key)
+	}
+	if url := set.Lookup("DATABASE_URL"); url.HasDef || !url.Required {
+		t.Fatalf("%+v", url)
+	}
+	if set.Lookup("UNKNOWN") != nil {
+		t.Fatal("unknown variable must be nil")
+	}
+
+	if err := set.Set("UNKNOWN", "x"); err == nil {
+		t.Fatal("unknown variable must be an error")
+	}
+	if err := set.Set("API_KEY", "x"); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Set("TIMEOUT", "x"); err == nil {
+		t.Fatal("invalid value must be an error")
+	}
+}
+
+func TestVariableSet_UsageMarkdown(t *testing.T) {
+
+	const want = "| Name | Default | Required | Description |\n" +
+		"| --- | --- | --- | --- |\n" +
+		"| `API_KEY` | `******` | no | api key |\n" +
+		"| `DATABASE_URL` |  | yes | database connection string |\n" +
+		"| `GREETING` | `hello \\| \"world\"` | no |  |\n" +
+		"| `HOSTS` | `a,b` | no | allowed hosts<br>separated by comma |\n" +
+		"| `PORT` | `8080` | no | listen port |\n" +
+		"| `TIMEOUT` | `30s` | no | request timeout |\n"
+
+	if got := newUsageTestSet().UsageMarkdown(); got != want {
+		t.Fatalf("want:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestVariableSet_UsageJSON(t *testing.T) {
+
+	b, err := newUsageTestSet().UsageJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var variables []map[string]interface{}
+	if err := json.Unmarshal(b, &variables); err != nil {
+		t.Fatal(err)
+	}
+	if len(variables) != 6 {
+		t.Fatal(string(b))
+	}
+
+	want := map[string]interface{}{
+		"name":      "API_KEY",
+		"default":   env.Redacted,
+		"required":  false,
+		"sensitive": true,
+		"usage":     "api key",
+	}
+	if !reflect.DeepEqual(variables[0], want) {
+		t.Fatal(variables[0])
+	}
+	if _, ok := variables[1]["default"]; ok {
+		t.Fatal(variables[1])
+	}
+}
+
+func TestVariableSet_UsageDotenv(t *testing.T) {
+
+	set := newUsageTestSet()
+	sample := set.UsageDotenv()
+
+	const want = "# api key\n" +
+		"# (sensitive)\n" +
+		"API_KEY=\n" +
+		"\n" +
+		"# database connection string\n" +
+		"# (required)\n" +
+		"DATABASE_URL=\n" +
+		"\n" +
+		"GREETING=\"hello | \\\"world\\\"\"\n" +
+		"\n" +
+		"# allowed hosts\n" +
+		"# separated by comma\n" +
+		"HOSTS=a,b\n" +
+		"\n" +
+		"# listen port\n" +
+		"PORT=8080\n" +
+		"\n" +
+		"# request timeout\n" +
+		"TIMEOUT=30s\n"
+	if sample != want {
+		t.Fatalf("want:\n%s\ngot:\n%s", want, sample)
+	}
+
+	// the sample is parsed as the default values
+	environments, err := env.ParseDotenv(strings.NewReader(sample), func(string) (string, bool) { return "", false })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Parse(append(environments, "DATABASE_URL=postgres://")); err != nil {
+		t.Fatal(err)
+	}
+	set.VisitAll(func(v *env.Variable) {
+		if !v.HasDef || v.Sensitive {
+			return
+		}
+		if s := v.Value.(interface{ String() string }).String(); s != v.DefValue {
+			t.Fatalf("%s want: %q got: %q", v.Name, v.DefValue, s)
+		}
+	})
+}
@@ -1,6 +1,16 @@
 package env
 
-import "strconv"
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.nanasi880.dev/x/bytes/byteutil"
+)
 
 // Value is the interface to the dynamic value stored in a value.
 type Value interface {
@@ -23,6 +33,10 @@ func (val *stringValue) Set(v string) error {
 	return nil
 }
 
+func (val *stringValue) String() string {
+	return *val.ptr
+}
+
 // intValue is Value type of int.
 type intValue struct {
 	ptr *int
@@ -36,7 +50,7 @@ func newIntValue(v *int) *intValue {
 
 func (val *intValue) Set(v string) error {
 
-	iv, err := strconv.ParseInt(v, 10, 32)
+	iv, err := strconv.ParseInt(v, 10, strconv.IntSize)
 	if err != nil {
 		return err
 	}
@@ -45,6 +59,10 @@ func (val *intValue) Set(v string) error {
 	return nil
 }
 
+func (val *intValue) String() string {
+	return strconv.Itoa(*val.ptr)
+}
+
 // boolValue is Value type of bool.
 type boolValue struct {
 	ptr *bool
@@ -64,3 +82,358 @@ func (val *boolValue) Set(v string) error {
 	*val.ptr = b
 	return nil
 }
+
+func (val *boolValue) String() string {
+	return strconv.FormatBool(*val.ptr)
+}
+
+// int64Value is Value type of int64.
+type int64Value struct {
+	ptr *int64
+}
+
+func newInt64Value(v *int64) *int64Value {
+	return &int64Value{
+		ptr: v,
+	}
+}
+
+func (val *int64Value) Set(v string) error {
+	iv, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return err
+	}
+	*val.ptr = iv
+	return nil
+}
+
+func (val *int64Value) String() string {
+	return strconv.FormatInt(*val.ptr, 10)
+}
+
+// uintValue is Value type of uint.
+type uintValue struct {
+	ptr *uint
+}
+
+func newUintValue(v *uint) *uintValue {
+	return &uintValue{
+		ptr: v,
+	}
+}
+
+func (val *uintValue) Set(v string) error {
+	uv, err := strconv.ParseUint(v, 10, strconv.IntSize)
+	if err != nil {
+		return err
+	}
+	*val.ptr = uint(uv)
+	return nil
+}
+
+func (val *uintValue) String() string {
+	return strconv.FormatUint(uint64(*val.ptr), 10)
+}
+
+// float64Value is Value type of float64.
+type float64Value struct {
+	ptr *float64
+}
+
+func newFloat64Value(v *float64) *float64Value {
+	return &float64Value{
+		ptr: v,
+	}
+}
+
+func (val *float64Value) Set(v string) error {
+	fv, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return err
+	}
+	*val.ptr = fv
+	return nil
+}
+
+func (val *float64Value) String() string {
+	return strconv.FormatFloat(*val.ptr, 'g', -1, 64)
+}
+
+// durationValue is Value type of time.Duration.
+type durationValue struct {
+	ptr *time.Duration
+}
+
+func newDurationValue(v *time.Duration) *durationValue {
+	return &durationValue{
+		ptr: v,
+	}
+}
+
+func (val *durationValue) Set(v string) error {
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return err
+	}
+	*val.ptr = d
+	return nil
+}
+
+func (val *durationValue) String() string {
+	return val.ptr.String()
+}
+
+// sizeValue is Value type of byteutil.Size.
+type sizeValue struct {
+	ptr *byteutil.Size
+}
+
+func newSizeValue(v *byteutil.Size) *sizeValue {
+	return &sizeValue{
+		ptr: v,
+	}
+}
+
+func (val *sizeValue) Set(v string) error {
+	size, err := byteutil.ParseSize(v)
+	if err != nil {
+		return err
+	}
+	*val.ptr = size
+	return nil
+}
+
+func (val *sizeValue) String() string {
+	return formatSize(*val.ptr)
+}
+
+// locationValue is Value type of *time.Location.
+type locationValue struct {
+	ptr **time.Location
+}
+
+func newLocationValue(v **time.Location) *locationValue {
+	return &locationValue{
+		ptr: v,
+	}
+}
+
+func (val *locationValue) Set(v string) error {
+	loc, err := time.LoadLocation(v)
+	if err != nil {
+		return err
+	}
+	*val.ptr = loc
+	return nil
+}
+
+func (val *locationValue) String() string {
+	if *val.ptr == nil {
+		return ""
+	}
+	return (*val.ptr).String()
+}
+
+// urlValue is Value type of *url.URL.
+type urlValue struct {
+	ptr **url.URL
+}
+
+func newURLValue(v **url.URL) *urlValue {
+	return &urlValue{
+		ptr: v,
+	}
+}
+
+func (val *urlValue) Set(v string) error {
+	u, err := url.Parse(v)
+	if err != nil {
+		return err
+	}
+	*val.ptr = u
+	return nil
+}
+
+func (val *urlValue) String() string {
+	if *val.ptr == nil {
+		return ""
+	}
+	return (*val.ptr).String()
+}
+
+// urlStructValue is Value type of url.URL.
+type urlStructValue struct {
+	ptr *url.URL
+}
+
+func newURLStructValue(v *url.URL) *urlStructValue {
+	return &urlStructValue{
+		ptr: v,
+	}
+}
+
+func (val *urlStructValue) Set(v string) error {
+	u, err := url.Parse(v)
+	if err != nil {
+		return err
+	}
+	*val.ptr = *u
+	return nil
+}
+
+func (val *urlStructValue) String() string {
+	return val.ptr.String()
+}
+
+// stringSliceValue is Value type of []string. The elements are separated by sep.
+type stringSliceValue struct {
+	ptr *[]string
+	sep string
+}
+
+func newStringSliceValue(v *[]string, sep string) *stringSliceValue {
+	return &stringSliceValue{
+		ptr: v,
+		sep: sep,
+	}
+}
+
+func (val *stringSliceValue) Set(v string) error {
+	*val.ptr = splitList(v, val.sep)
+	return nil
+}
+
+func (val *stringSliceValue) String() string {
+	return strings.Join(*val.ptr, val.sep)
+}
+
+// intSliceValue is Value type of []int. The elements are separated by sep.
+type intSliceValue struct {
+	ptr *[]int
+	sep string
+}
+
+func newIntSliceValue(v *[]int, sep string) *intSliceValue {
+	return &intSliceValue{
+		ptr: v,
+		sep: sep,
+	}
+}
+
+func (val *intSliceValue) Set(v string) error {
+	elements := splitList(v, val.sep)
+	values := make([]int, 0, len(elements))
+	for _, e := range elements {
+		iv, err := strconv.ParseInt(e, 10, strconv.IntSize)
+		if err != nil {
+			return err
+		}
+		values = append(values, int(iv))
+	}
+	*val.ptr = values
+	return nil
+}
+
+func (val *intSliceValue) String() string {
+	elements := make([]string, len(*val.ptr))
+	for i, v := range *val.ptr {
+		elements[i] = strconv.Itoa(v)
+	}
+	return strings.Join(elements, val.sep)
+}
+
+// stringMapValue is Value type of map[string]string. The pairs are separated by sep, and the key and the value are separated by "=".
+type stringMapValue struct {
+	ptr *map[string]string
+	sep string
+}
+
+func newStringMapValue(v *map[string]string, sep string) *stringMapValue {
+	return &stringMapValue{
+		ptr: v,
+		sep: sep,
+	}
+}
+
+func (val *stringMapValue) Set(v string) error {
+	m := make(map[string]string)
+	for _, pair := range splitList(v, val.sep) {
+		index := strings.IndexByte(pair, '=')
+		if index < 0 {
+			return fmt.Errorf("invalid key value pair `%s`", pair)
+		}
+		m[strings.TrimSpace(pair[:index])] = strings.TrimSpace(pair[index+1:])
+	}
+	*val.ptr = m
+	return nil
+}
+
+func (val *stringMapValue) String() string {
+	keys := make([]string, 0, len(*val.ptr))
+	for k := range *val.ptr {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + (*val.ptr)[k]
+	}
+	return strings.Join(pairs, val.sep)
+}
+
+// textValue is Value type of encoding.TextUnmarshaler.
+type textValue struct {
+	ptr encoding.TextUnmarshaler
+}
+
+func newTextValue(v encoding.TextUnmarshaler) *textValue {
+	return &textValue{
+		ptr: v,
+	}
+}
+
+func (val *textValue) Set(v string) error {
+	return val.ptr.UnmarshalText([]byte(v))
+}
+
+func (val *textValue) String() string {
+	m, ok := val.ptr.(encoding.TextMarshaler)
+	if !ok {
+		return ""
+	}
+	b, err := m.MarshalText()
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// formatSize formats the size with the largest unit which divides the size, so that the result is parsed by byteutil.ParseSize.
+func formatSize(size byteutil.Size) string {
+	units := []struct {
+		size byteutil.Size
+		name string
+	}{
+		{byteutil.EiB, "EiB"}, {byteutil.PiB, "PiB"}, {byteutil.TiB, "TiB"}, {byteutil.GiB, "GiB"}, {byteutil.MiB, "MiB"}, {byteutil.KiB, "KiB"},
+		{byteutil.EB, "EB"}, {byteutil.PB, "PB"}, {byteutil.TB, "TB"}, {byteutil.GB, "GB"}, {byteutil.MB, "MB"}, {byteutil.KB, "KB"},
+	}
+	for _, unit := range units {
+		if size != 0 && size%unit.size == 0 {
+			return strconv.FormatInt((size/unit.size).Int64(), 10) + unit.name
+		}
+	}
+	return strconv.FormatInt(size.Int64(), 10) + "B"
+}
+
+// splitList splits the list value by sep, and trims the spaces of the elements. The empty value is the empty list.
+func splitList(v string, sep string) []string {
+	if v == "" {
+		return []string{}
+	}
+	elements := strings.Split(v, sep)
+	for i, e := range elements {
+		elements[i] = strings.TrimSpace(e)
+	}
+	return elements
+}
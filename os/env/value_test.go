@@ -0,0 +1,182 @@
+package env_test
+
+import (
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.nanasi880.dev/x/bytes/byteutil"
+	"go.nanasi880.dev/x/os/env"
+)
+
+type upperValue struct {
+	s string
+}
+
+func (v *upperValue) Set(s string) error {
+	v.s = strings.ToUpper(s)
+	return nil
+}
+
+func TestVariableSet_Values(t *testing.T) {
+
+	set := env.NewVariableSet("test", env.ContinueOnError)
+
+	var (
+		i64      = set.Int64("I64", 0, "usage")
+		u        = set.Uint("U", 0, "usage")
+		f64      = set.Float64("F64", 0, "usage")
+		duration = set.Duration("DURATION", time.Second, "usage")
+		size     = set.Size("SIZE", 0, "usage")
+		location = set.Location("LOCATION", time.UTC, "usage")
+		u2       = set.URL("URL", nil, "usage")
+		strs     = set.StringSlice("STRINGS", nil, "usage")
+		ints     = set.IntSlice("INTS", nil, "usage")
+		m        = set.StringMap("MAP", nil, "usage")
+		ip       net.IP
+		upper    upperValue
+	)
+	set.TextVar(&ip, "IP", "127.0.0.1", "usage")
+	set.Var(&upper, "UPPER", "usage")
+
+	set.Separator = ";"
+	semicolon := set.StringSlice("SEMICOLON", []string{"default"}, "usage")
+
+	err := set.Parse([]string{
+		"I64=-9223372036854775808",
+		"U=42",
+		"F64=1.5",
+		"SIZE=512MiB",
+		"LOCATION=Asia/Tokyo",
+		"URL=https://example.com/path?q=1",
+		"STRINGS=a, b,c",
+		"INTS=1,2,3",
+		"MAP=a=1, b = 2",
+		"IP=192.168.0.1",
+		"UPPER=value",
+		"SEMICOLON=a,b;c",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *i64 != -9223372036854775808 || *u != 42 || *f64 != 1.5 {
+		t.Fatal(*i64, *u, *f64)
+	}
+	if *duration != time.Second {
+		t.Fatal(*duration)
+	}
+	if *size != 512*byteutil.MiB {
+		t.Fatal(*size)
+	}
+	if (*location).String() != "Asia/Tokyo" {
+		t.Fatal(*location)
+	}
+	if *u2 == nil || (*u2).Host != "example.com" || (*u2).Query().Get("q") != "1" {
+		t.Fatal(*u2)
+	}
+	if !reflect.DeepEqual(*strs, []string{"a", "b", "c"}) {
+		t.Fatal(*strs)
+	}
+	if !reflect.DeepEqual(*ints, []int{1, 2, 3}) {
+		t.Fatal(*ints)
+	}
+	if !reflect.DeepEqual(*m, map[string]string{"a": "1", "b": "2"}) {
+		t.Fatal(*m)
+	}
+	if ip.String() != "192.168.0.1" {
+		t.Fatal(ip)
+	}
+	if upper.s != "VALUE" {
+		t.Fatal(upper.s)
+	}
+	if !reflect.DeepEqual(*semicolon, []string{"a,b", "c"}) {
+		t.Fatal(*semicolon)
+	}
+}
+
+func TestVariableSet_Int(t *testing.T) {
+
+	if strconv.IntSize < 64 {
+		t.Skip("int is 32-bit")
+	}
+
+	set := env.NewVariableSet("test", env.ContinueOnError)
+	n := set.Int("N", 0, "usage")
+	if err := set.Parse([]string{"N=3000000000"}); err != nil {
+		t.Fatal(err)
+	}
+	if *n != 3000000000 {
+		t.Fatal(*n)
+	}
+}
+
+func TestVariableSet_Values_Error(t *testing.T) {
+
+	testSuites := []struct {
+		define func(set *env.VariableSet)
+		value  string
+	}{
+		{define: func(set *env.VariableSet) { set.Int64("V", 0, "") }, value: "x"},
+		{define: func(set *env.VariableSet) { set.Uint("V", 0, "") }, value: "-1"},
+		{define: func(set *env.VariableSet) { set.Float64("V", 0, "") }, value: "x"},
+		{define: func(set *env.VariableSet) { set.Duration("V", 0, "") }, value: "10"},
+		{define: func(set *env.VariableSet) { set.Size("V", 0, "") }, value: "10XB"},
+		{define: func(set *env.VariableSet) { set.Location("V", nil, "") }, value: "Nowhere/City"},
+		{define: func(set *env.VariableSet) { set.URL("V", nil, "") }, value: "://"},
+		{define: func(set *env.VariableSet) { set.IntSlice("V", nil, "") }, value: "1,x"},
+		{define: func(set *env.VariableSet) { set.StringMap("V", nil, "") }, value: "a"},
+	}
+
+	for i, suite := range testSuites {
+		set := env.NewVariableSet("test", env.ContinueOnError)
+		suite.define(set)
+		if err := set.Parse([]string{"V=" + suite.value}); err == nil {
+			t.Fatalf("suite:%d %s must be an error", i, suite.value)
+		}
+	}
+}
+
+func TestVariableSet_Struct_Values(t *testing.T) {
+
+	var cfg struct {
+		Timeout time.Duration     `default:"30s"`
+		MaxBody byteutil.Size     `default:"1MiB"`
+		Hosts   []string          `sep:" "`
+		Ports   []int             `default:"80,443"`
+		Labels  map[string]string `env:"LABELS"`
+		Addr    net.IP            `default:"::1"`
+		Since   time.Time
+		Name    upperValue
+	}
+
+	set := env.NewVariableSet("test", env.ContinueOnError)
+	if err := set.Struct(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	err := set.Parse([]string{
+		"HOSTS=a b",
+		"LABELS=k=v",
+		"SINCE=2021-01-02T03:04:05Z",
+		"NAME=abc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Timeout != 30*time.Second || cfg.MaxBody != byteutil.MiB {
+		t.Fatal(cfg.Timeout, cfg.MaxBody)
+	}
+	if !reflect.DeepEqual(cfg.Hosts, []string{"a", "b"}) || !reflect.DeepEqual(cfg.Ports, []int{80, 443}) {
+		t.Fatal(cfg.Hosts, cfg.Ports)
+	}
+	if cfg.Labels["k"] != "v" || cfg.Addr.String() != "::1" || cfg.Name.s != "ABC" {
+		t.Fatal(cfg.Labels, cfg.Addr, cfg.Name)
+	}
+	if !cfg.Since.Equal(time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Fatal(cfg.Since)
+	}
+}
@@ -0,0 +1,72 @@
+package env
+
+import (
+	"fmt"
+)
+
+// A Variable represents the state of an environment variable.
+type Variable struct {
+	Name      string // Name is the name of the environment variable.
+	Usage     string // Usage is the usage string.
+	Value     Value  // Value is the value as set.
+	DefValue  string // DefValue is the default value as text. If the variable is sensitive, DefValue is redacted.
+	HasDef    bool   // HasDef is whether the variable has the default value.
+	Required  bool   // Required is whether the variable must be set.
+	Sensitive bool   // Sensitive is whether the value is redacted.
+}
+
+func newVariable(v *variable) *Variable {
+	defValue := v.defValue
+	if v.sensitive && v.def != nil {
+		defValue = Redacted
+	}
+	return &Variable{
+		Name:      v.name,
+		Usage:     v.usage,
+		Value:     v.value,
+		DefValue:  defValue,
+		HasDef:    v.def != nil && !v.required,
+		Required:  v.required,
+		Sensitive: v.sensitive,
+	}
+}
+
+// VisitAll visits the variables in lexicographical order, calling fn for each.
+// It visits all variables, even those not set.
+func (set *VariableSet) VisitAll(fn func(*Variable)) {
+	for _, key := range set.sortedKeys() {
+		fn(newVariable(set.variables[key]))
+	}
+}
+
+// Visit visits the variables in lexicographical order, calling fn for each.
+// It visits only those variables that have been set by Parse or Set.
+func (set *VariableSet) Visit(fn func(*Variable)) {
+	for _, key := range set.sortedKeys() {
+		if v, ok := set.actual[key]; ok {
+			fn(newVariable(v))
+		}
+	}
+}
+
+// Lookup returns the Variable of the named environment variable, returning nil if none exists.
+func (set *VariableSet) Lookup(name string) *Variable {
+	v, ok := set.variables[name]
+	if !ok {
+		return nil
+	}
+	return newVariable(v)
+}
+
+// Set sets the value of the named environment variable.
+// The error is *VariableError if the value is invalid.
+func (set *VariableSet) Set(name string, value string) error {
+	v, ok := set.variables[name]
+	if !ok {
+		return fmt.Errorf("no such variable %s", name)
+	}
+	if err := set.set(v, value); err != nil {
+		return err
+	}
+	return nil
+}
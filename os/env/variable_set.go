@@ -1,18 +1,40 @@
 package env
 
 import (
+	"encoding"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"sort"
 	"strings"
+	"time"
+
+	"go.nanasi880.dev/x/bytes/byteutil"
 )
 
 type variable struct {
-	name  string
-	value Value
-	def   interface{}
-	usage string
+	name      string
+	value     Value
+	def       interface{}
+	defValue  string // defValue is the default value as text.
+	usage     string
+	required  bool
+	sensitive bool
+}
+
+// describe returns the description of the default value, "required", "default(value)" or the empty string.
+func (v *variable) describe() string {
+	switch {
+	case v.required:
+		return "required"
+	case v.def != nil && v.sensitive:
+		return fmt.Sprintf("default(%s)", Redacted)
+	case v.def != nil:
+		return fmt.Sprintf("default(%s)", v.defValue)
+	default:
+		return ""
+	}
 }
 
 // A VariableSet represents a set of defined environment variable. The zero value of a FlagSet
@@ -22,8 +44,12 @@ type variable struct {
 // name is already in use will cause a panic.
 type VariableSet struct {
 	Usage       func() string
+	Separator   string        // Separator is the separator of the elements of the slice and map variables defined after it is set. The default is ",".
+	FileSuffix  string        // FileSuffix is the suffix of the variable whose value is the path of the file containing the value, e.g. "_FILE". If empty, the files are not read.
+	MaxFileSize byteutil.Size // MaxFileSize is the maximum size of the file read by FileSuffix. The default is 64KiB.
 	name        string
 	variables   map[string]*variable
+	actual      map[string]*variable
 	errHandling ErrorHandling
 	output      io.Writer
 }
@@ -99,49 +125,311 @@ func (set *VariableSet) BoolVar(p *bool, name string, def bool, usage string) {
 	set.value(bv, def, name, usage)
 }
 
+// Int64 defines a int64 value with specified name, default value, and usage string.
+// The return value is the address of a int64 variable that stores the value of the environment variable.
+func (set *VariableSet) Int64(name string, def int64, usage string) *int64 {
+	iv := newInt64Value(&def)
+	set.value(iv, def, name, usage)
+	return iv.ptr
+}
+
+// Int64Var defines a int64 environment variable with specified name, default value, and usage string.
+// The argument p points to a int64 variable in which to store the value of the environment variable.
+func (set *VariableSet) Int64Var(p *int64, name string, def int64, usage string) {
+	if p == nil {
+		panic("`p` cannot be nil")
+	}
+	*p = def
+	iv := newInt64Value(p)
+	set.value(iv, def, name, usage)
+}
+
+// Uint defines a uint value with specified name, default value, and usage string.
+// The return value is the address of a uint variable that stores the value of the environment variable.
+func (set *VariableSet) Uint(name string, def uint, usage string) *uint {
+	uv := newUintValue(&def)
+	set.value(uv, def, name, usage)
+	return uv.ptr
+}
+
+// UintVar defines a uint environment variable with specified name, default value, and usage string.
+// The argument p points to a uint variable in which to store the value of the environment variable.
+func (set *VariableSet) UintVar(p *uint, name string, def uint, usage string) {
+	if p == nil {
+		panic("`p` cannot be nil")
+	}
+	*p = def
+	uv := newUintValue(p)
+	set.value(uv, def, name, usage)
+}
+
+// Float64 defines a float64 value with specified name, default value, and usage string.
+// The return value is the address of a float64 variable that stores the value of the environment variable.
+func (set *VariableSet) Float64(name string, def float64, usage string) *float64 {
+	fv := newFloat64Value(&def)
+	set.value(fv, def, name, usage)
+	return fv.ptr
+}
+
+// Float64Var defines a float64 environment variable with specified name, default value, and usage string.
+// The argument p points to a float64 variable in which to store the value of the environment variable.
+func (set *VariableSet) Float64Var(p *float64, name string, def float64, usage string) {
+	if p == nil {
+		panic("`p` cannot be nil")
+	}
+	*p = def
+	fv := newFloat64Value(p)
+	set.value(fv, def, name, usage)
+}
+
+// Duration defines a time.Duration value with specified name, default value, and usage string. The value is parsed by time.ParseDuration.
+// The return value is the address of a time.Duration variable that stores the value of the environment variable.
+func (set *VariableSet) Duration(name string, def time.Duration, usage string) *time.Duration {
+	dv := newDurationValue(&def)
+	set.value(dv, def, name, usage)
+	return dv.ptr
+}
+
+// DurationVar defines a time.Duration environment variable with specified name, default value, and usage string. The value is parsed by time.ParseDuration.
+// The argument p points to a time.Duration variable in which to store the value of the environment variable.
+func (set *VariableSet) DurationVar(p *time.Duration, name string, def time.Duration, usage string) {
+	if p == nil {
+		panic("`p` cannot be nil")
+	}
+	*p = def
+	dv := newDurationValue(p)
+	set.value(dv, def, name, usage)
+}
+
+// Size defines a byteutil.Size value with specified name, default value, and usage string. The value is parsed by byteutil.ParseSize, e.g. 512MiB.
+// The return value is the address of a byteutil.Size variable that stores the value of the environment variable.
+func (set *VariableSet) Size(name string, def byteutil.Size, usage string) *byteutil.Size {
+	sv := newSizeValue(&def)
+	set.value(sv, def, name, usage)
+	return sv.ptr
+}
+
+// SizeVar defines a byteutil.Size environment variable with specified name, default value, and usage string. The value is parsed by byteutil.ParseSize, e.g. 512MiB.
+// The argument p points to a byteutil.Size variable in which to store the value of the environment variable.
+func (set *VariableSet) SizeVar(p *byteutil.Size, name string, def byteutil.Size, usage string) {
+	if p == nil {
+		panic("`p` cannot be nil")
+	}
+	*p = def
+	sv := newSizeValue(p)
+	set.value(sv, def, name, usage)
+}
+
+// Location defines a *time.Location value with specified name, default value, and usage string. The value is parsed by time.LoadLocation.
+// The return value is the address of a *time.Location variable that stores the value of the environment variable.
+func (set *VariableSet) Location(name string, def *time.Location, usage string) **time.Location {
+	lv := newLocationValue(&def)
+	set.value(lv, def, name, usage)
+	return lv.ptr
+}
+
+// LocationVar defines a *time.Location environment variable with specified name, default value, and usage string. The value is parsed by time.LoadLocation.
+// The argument p points to a *time.Location variable in which to store the value of the environment variable.
+func (set *VariableSet) LocationVar(p **time.Location, name string, def *time.Location, usage string) {
+	if p == nil {
+		panic("`p` cannot be nil")
+	}
+	*p = def
+	lv := newLocationValue(p)
+	set.value(lv, def, name, usage)
+}
+
+// URL defines a *url.URL value with specified name, default value, and usage string. The value is parsed by url.Parse.
+// The return value is the address of a *url.URL variable that stores the value of the environment variable.
+func (set *VariableSet) URL(name string, def *url.URL, usage string) **url.URL {
+	uv := newURLValue(&def)
+	set.value(uv, def, name, usage)
+	return uv.ptr
+}
+
+// URLVar defines a *url.URL environment variable with specified name, default value, and usage string. The value is parsed by url.Parse.
+// The argument p points to a *url.URL variable in which to store the value of the environment variable.
+func (set *VariableSet) URLVar(p **url.URL, name string, def *url.URL, usage string) {
+	if p == nil {
+		panic("`p` cannot be nil")
+	}
+	*p = def
+	uv := newURLValue(p)
+	set.value(uv, def, name, usage)
+}
+
+// StringSlice defines a []string value with specified name, default value, and usage string. The elements are separated by Separator.
+// The return value is the address of a []string variable that stores the value of the environment variable.
+func (set *VariableSet) StringSlice(name string, def []string, usage string) *[]string {
+	sv := newStringSliceValue(&def, set.separator())
+	set.value(sv, def, name, usage)
+	return sv.ptr
+}
+
+// StringSliceVar defines a []string environment variable with specified name, default value, and usage string. The elements are separated by Separator.
+// The argument p points to a []string variable in which to store the value of the environment variable.
+func (set *VariableSet) StringSliceVar(p *[]string, name string, def []string, usage string) {
+	if p == nil {
+		panic("`p` cannot be nil")
+	}
+	*p = def
+	sv := newStringSliceValue(p, set.separator())
+	set.value(sv, def, name, usage)
+}
+
+// IntSlice defines a []int value with specified name, default value, and usage string. The elements are separated by Separator.
+// The return value is the address of a []int variable that stores the value of the environment variable.
+func (set *VariableSet) IntSlice(name string, def []int, usage string) *[]int {
+	iv := newIntSliceValue(&def, set.separator())
+	set.value(iv, def, name, usage)
+	return iv.ptr
+}
+
+// IntSliceVar defines a []int environment variable with specified name, default value, and usage string. The elements are separated by Separator.
+// The argument p points to a []int variable in which to store the value of the environment variable.
+func (set *VariableSet) IntSliceVar(p *[]int, name string, def []int, usage string) {
+	if p == nil {
+		panic("`p` cannot be nil")
+	}
+	*p = def
+	iv := newIntSliceValue(p, set.separator())
+	set.value(iv, def, name, usage)
+}
+
+// StringMap defines a map[string]string value with specified name, default value, and usage string. The pairs of key=value are separated by Separator.
+// The return value is the address of a map[string]string variable that stores the value of the environment variable.
+func (set *VariableSet) StringMap(name string, def map[string]string, usage string) *map[string]string {
+	sv := newStringMapValue(&def, set.separator())
+	set.value(sv, def, name, usage)
+	return sv.ptr
+}
+
+// StringMapVar defines a map[string]string environment variable with specified name, default value, and usage string. The pairs of key=value are separated by Separator.
+// The argument p points to a map[string]string variable in which to store the value of the environment variable.
+func (set *VariableSet) StringMapVar(p *map[string]string, name string, def map[string]string, usage string) {
+	if p == nil {
+		panic("`p` cannot be nil")
+	}
+	*p = def
+	sv := newStringMapValue(p, set.separator())
+	set.value(sv, def, name, usage)
+}
+
+// TextVar defines an environment variable with specified name, default value, and usage string.
+// The value is parsed by the UnmarshalText method of p, and the default value is parsed as the same.
+func (set *VariableSet) TextVar(p encoding.TextUnmarshaler, name string, def string, usage string) {
+	if p == nil {
+		panic("`p` cannot be nil")
+	}
+	tv := newTextValue(p)
+	if err := tv.Set(def); err != nil {
+		msg := fmt.Sprintf("invalid default value of %s: %v", name, err)
+		panic(msg)
+	}
+	set.value(tv, def, name, usage)
+}
+
+// Var defines an environment variable with specified name and usage string.
+// The type and value of the variable are represented by the first argument, of type Value,
+// which typically holds a user-defined implementation of Value.
+func (set *VariableSet) Var(value Value, name string, usage string) {
+	if value == nil {
+		panic("`value` cannot be nil")
+	}
+	set.value(value, nil, name, usage)
+}
+
 // Parse parses the environment variables from argument. Must be called
 // after all variables are defined and before variable are accessed by the program.
+// Parse parses all variables even if some of them fail, and the failures and the required variables which are not set
+// are reported together as ParseErrors.
+// If FileSuffix is not empty, the value of the variable NAME is read from the file specified by the variable NAME + FileSuffix.
 func (set *VariableSet) Parse(environments []string) error {
+	return set.parse(environments, nil)
+}
+
+// parse parses the environment variables, except the variables in overridden which are regarded as already set,
+// e.g. by the flags bound to them.
+func (set *VariableSet) parse(environments []string, overridden map[string]bool) error {
+
+	var errs ParseErrors
 
 	keys := set.sortedKeys()
 	for _, key := range keys {
 		val := set.variables[key]
 
+		if overridden[key] {
+			set.setActual(val)
+			continue
+		}
+
 		osVal, found := set.lookup(environments, key)
+		if content, ok, fileErr := set.lookupFile(environments, key); fileErr != nil {
+			fileErr.Usage = val.usage
+			errs = append(errs, fileErr)
+			continue
+		} else if ok {
+			osVal, found = content, true
+		}
 		if !found {
+			if val.required {
+				errs = append(errs, &VariableError{
+					Name:  key,
+					Usage: val.usage,
+					Err:   ErrRequired,
+				})
+			}
 			continue
 		}
 
-		err := val.value.Set(osVal)
-		if err == nil {
-			continue
+		if err := set.set(val, osVal); err != nil {
+			errs = append(errs, err)
 		}
+	}
 
-		e := fmt.Errorf("parse error %s: %w", key, err)
-		switch set.errHandling {
+	if len(errs) > 0 {
+		return set.fail(errs)
+	}
+	return nil
+}
 
-		case ContinueOnError:
-			return e
+// Required marks the defined variables as required. Parse reports the required variables which are not set.
+func (set *VariableSet) Required(names ...string) {
+	for _, name := range names {
+		val, ok := set.variables[name]
+		if !ok {
+			msg := fmt.Sprintf("%s is not defined", name)
+			panic(msg)
+		}
+		val.required = true
+	}
+}
 
-		case ExitOnError:
-			w := set.output
-			if w == nil {
-				w = os.Stderr
-			}
-			set.fprintf(w, "%v\n", e)
-			os.Exit(2)
+// fail handles the error of Parse according to the error handling property.
+func (set *VariableSet) fail(e error) error {
 
-		default:
-			fallthrough
-		case PanicOnError:
-			panic(e)
+	switch set.errHandling {
+
+	case ContinueOnError:
+		return e
+
+	case ExitOnError:
+		w := set.output
+		if w == nil {
+			w = os.Stderr
 		}
+		set.fprintf(w, "%v\n", e)
+		os.Exit(2)
+		return e
+
+	default:
+		fallthrough
+	case PanicOnError:
+		panic(e)
 	}
-
-	return nil
 }
 
-func (set *VariableSet) value(v Value, def interface{}, name string, usage string) {
+func (set *VariableSet) value(v Value, def interface{}, name string, usage string) *variable {
 
 	if set.variables == nil {
 		set.variables = make(map[string]*variable)
@@ -154,12 +442,52 @@ func (set *VariableSet) value(v Value, def interface{}, name string, usage strin
 	}
 
 	val := &variable{
-		name:  name,
-		value: v,
-		def:   def,
-		usage: usage,
+		name:     name,
+		value:    v,
+		def:      def,
+		defValue: "",
+		usage:    usage,
+	}
+	if def != nil {
+		// the value has the default value at this point
+		if s, ok := v.(fmt.Stringer); ok {
+			val.defValue = s.String()
+		} else {
+			val.defValue = fmt.Sprint(def)
+		}
 	}
 	set.variables[name] = val
+
+	return val
+}
+
+// set sets the value of the variable, and records the variable as set.
+func (set *VariableSet) set(val *variable, value string) *VariableError {
+
+	err := val.value.Set(value)
+	if err != nil {
+		if val.sensitive {
+			err = &redactedError{err: err, value: value}
+			value = Redacted
+		}
+		return &VariableError{
+			Name:  val.name,
+			Value: value,
+			Usage: val.usage,
+			Err:   err,
+		}
+	}
+
+	set.setActual(val)
+	return nil
+}
+
+// setActual records that the variable is set.
+func (set *VariableSet) setActual(val *variable) {
+	if set.actual == nil {
+		set.actual = make(map[string]*variable)
+	}
+	set.actual[val.name] = val
 }
 
 func (set *VariableSet) defaultUsage() string {
@@ -173,8 +501,8 @@ func (set *VariableSet) defaultUsage() string {
 	for _, key := range keys {
 		variable := set.variables[key]
 
-		if variable.def != nil {
-			set.fprintf(&sb, "  %s: default(%v)\n", variable.name, variable.def)
+		if desc := variable.describe(); desc != "" {
+			set.fprintf(&sb, "  %s: %s\n", variable.name, desc)
 		} else {
 			set.fprintf(&sb, "  %s:\n", variable.name)
 		}
@@ -187,6 +515,13 @@ func (set *VariableSet) defaultUsage() string {
 	return sb.String()
 }
 
+func (set *VariableSet) separator() string {
+	if set.Separator == "" {
+		return ","
+	}
+	return set.Separator
+}
+
 func (_ *VariableSet) fprintf(w io.Writer, format string, args ...interface{}) {
 	_, _ = fmt.Fprintf(w, format, args...)
 }
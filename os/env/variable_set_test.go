@@ -1,6 +1,7 @@
 package env_test
 
 import (
+	"errors"
 	"os"
 	"testing"
 
@@ -38,3 +39,57 @@ func TestVariableSet(t *testing.T) {
 		t.Fatal(*bp)
 	}
 }
+
+func TestVariableSet_Parse_Errors(t *testing.T) {
+
+	set := env.NewVariableSet("test", env.ContinueOnError)
+	set.String("DATABASE_URL", "", "database connection string")
+	set.Int("PORT", 8080, "listen port\nmust be a number")
+	set.Bool("DEBUG", false, "")
+	set.String("TOKEN", "", "")
+	set.Required("DATABASE_URL", "TOKEN")
+
+	err := set.Parse([]string{"PORT=http", "DEBUG=maybe", "TOKEN=x"})
+	if err == nil {
+		t.Fatal("errors must be reported")
+	}
+
+	var errs env.ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 3 {
+		t.Fatalf("%#v", err)
+	}
+	if errs[0].Name != "DATABASE_URL" || !errors.Is(errs[0], env.ErrRequired) {
+		t.Fatal(errs[0])
+	}
+	if errs[1].Name != "DEBUG" || errs[1].Value != "maybe" {
+		t.Fatal(errs[1])
+	}
+	if errs[2].Name != "PORT" || errs[2].Value != "http" {
+		t.Fatal(errs[2])
+	}
+
+	const want = "env: 3 errors occurred:\n" +
+		"\tenv: DATABASE_URL: variable is required\n" +
+		"\t\tdatabase connection string\n" +
+		"\tenv: DEBUG: invalid value \"maybe\": strconv.ParseBool: parsing \"maybe\": invalid syntax\n" +
+		"\tenv: PORT: invalid value \"http\": strconv.ParseInt: parsing \"http\": invalid syntax\n" +
+		"\t\tlisten port\n" +
+		"\t\tmust be a number"
+	if err.Error() != want {
+		t.Fatalf("want: %q got: %q", want, err.Error())
+	}
+}
+
+func TestVariableSet_Parse_PanicOnError(t *testing.T) {
+
+	set := env.NewVariableSet("test", env.PanicOnError)
+	set.String("TOKEN", "", "")
+	set.Required("TOKEN")
+
+	defer func() {
+		if _, ok := recover().(env.ParseErrors); !ok {
+			t.Fatal("ParseErrors must be panicked")
+		}
+	}()
+	_ = set.Parse(nil)
+}